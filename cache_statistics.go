@@ -0,0 +1,35 @@
+package main
+
+import (
+	"math/bits"
+	"sync/atomic"
+)
+
+// cacheEntryCount returns the largest power of two of entries of entrySize bytes that fits in
+// sizeMB, or 0 when not even one does.
+func cacheEntryCount(sizeMB int, entrySize uint64) uint64 {
+	entryCount := uint64(sizeMB) * 1024 * 1024 / entrySize
+	if entryCount == 0 {
+		return 0
+	}
+	return 1 << (63 - bits.LeadingZeros64(entryCount))
+}
+
+type cacheStatistics struct {
+	probeCount atomic.Uint64
+	hitCount   atomic.Uint64
+}
+
+func (statistics *cacheStatistics) resetStatistics() {
+	statistics.probeCount.Store(0)
+	statistics.hitCount.Store(0)
+}
+
+// HitRate returns the number of probes, hits and the hit percentage since the last Clear.
+func (statistics *cacheStatistics) HitRate() (uint64, uint64, float64) {
+	probeCount, hitCount := statistics.probeCount.Load(), statistics.hitCount.Load()
+	if probeCount == 0 {
+		return 0, 0, 0
+	}
+	return probeCount, hitCount, 100 * float64(hitCount) / float64(probeCount)
+}
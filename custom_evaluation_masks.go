@@ -13,20 +13,57 @@ var CheckForIsolatedPawnOnFileMasks [8]chessEngine.Bitboard
 var CheckDoublePawnOnSquareMask [2][64]chessEngine.Bitboard
 var CheckPassedPawnOnSquareMask [2][64]chessEngine.Bitboard
 var CheckOutpostOnSquareMask [2][64]chessEngine.Bitboard
+var CheckBackwardPawnOnSquareMask [2][64]chessEngine.Bitboard
+var PawnStopSquares [2][64]uint8
+var SquareDistances [64][64]uint8
 var KingSafetyZonesOnSquareMask [64]KingSafetyZone
+var SpaceMasks [2]chessEngine.Bitboard
+var CentralFilesMask chessEngine.Bitboard
+var QueenSideFilesMask chessEngine.Bitboard
+var KingSideFilesMask chessEngine.Bitboard
+var LightSquaresMask chessEngine.Bitboard
+var DarkSquaresMask chessEngine.Bitboard
 
 func InitEvaluationRelatedMasks() {
+	computeSquareColorMasks()
+	computeSpaceMasks()
 	for file := chessEngine.FileA; file <= chessEngine.FileH; file++ {
 		computeCheckIsolatedPawnOnFileMask(uint8(file))
 	}
 	for square := 0; square < 64; square++ {
 		computeCheckDoublePawnOnSquareMask(uint8(square))
 		computeOutpostOnSquareMask(uint8(square))
+		computeCheckBackwardPawnOnSquareMask(uint8(square))
+		computePawnStopSquares(uint8(square))
+		computeSquareDistances(uint8(square))
 		computeKingSafetyZonesOnSquareMask(uint8(square))
 		computeCheckPassedPawnOnSquareMask(uint8(square))
 	}
 }
 
+// computeSquareColorMasks splits the board into light and dark squares; a1 is dark.
+func computeSquareColorMasks() {
+	for square := uint8(0); square < 64; square++ {
+		if (chessEngine.File(square)+chessEngine.Rank(square))%2 != 0 {
+			LightSquaresMask |= chessEngine.BitboardForSquare[square]
+		} else {
+			DarkSquaresMask |= chessEngine.BitboardForSquare[square]
+		}
+	}
+}
+
+// computeSpaceMasks sets the wing and central file masks and the central files, c to f, on each
+// side's second to fourth ranks.
+func computeSpaceMasks() {
+	for file := chessEngine.FileA; file <= chessEngine.FileD; file++ {
+		QueenSideFilesMask |= chessEngine.SetFileMasks[file]
+		KingSideFilesMask |= chessEngine.SetFileMasks[file+4]
+	}
+	CentralFilesMask = chessEngine.SetFileMasks[chessEngine.FileC] | chessEngine.SetFileMasks[chessEngine.FileD] | chessEngine.SetFileMasks[chessEngine.FileE] | chessEngine.SetFileMasks[chessEngine.FileF]
+	SpaceMasks[chessEngine.White] = CentralFilesMask & (chessEngine.SetRankMasks[chessEngine.Rank2] | chessEngine.SetRankMasks[chessEngine.Rank3] | chessEngine.SetRankMasks[chessEngine.Rank4])
+	SpaceMasks[chessEngine.Black] = CentralFilesMask & (chessEngine.SetRankMasks[chessEngine.Rank5] | chessEngine.SetRankMasks[chessEngine.Rank6] | chessEngine.SetRankMasks[chessEngine.Rank7])
+}
+
 func computeCheckIsolatedPawnOnFileMask(file uint8) {
 	var bitboardForFile chessEngine.Bitboard = chessEngine.SetFileMasks[file]
 	var CheckIsolatedPawnOnFileMask chessEngine.Bitboard = ((bitboardForFile & chessEngine.ClearFileMasks[chessEngine.FileA]) << 1) | ((bitboardForFile & chessEngine.ClearFileMasks[chessEngine.FileH]) >> 1)
@@ -80,6 +117,55 @@ func computeOutpostOnSquareMask(square uint8) {
 	}
 	CheckOutpostOnSquareMask[chessEngine.Black][square] = blackMask & ^bitboardForFile
 }
+
+// computeCheckBackwardPawnOnSquareMask marks the adjacent-file squares level with or behind the
+// square, where a friendly pawn could still support a pawn on the square.
+func computeCheckBackwardPawnOnSquareMask(square uint8) {
+	var currentAdjacentFilesMask chessEngine.Bitboard = CheckForIsolatedPawnOnFileMasks[chessEngine.File(square)]
+	squareRank := int(chessEngine.Rank(square))
+	whiteMask := currentAdjacentFilesMask
+	for rank := squareRank + 1; rank <= 7; rank++ {
+		whiteMask &= chessEngine.ClearRankMasks[rank]
+	}
+	CheckBackwardPawnOnSquareMask[chessEngine.White][square] = whiteMask
+
+	blackMask := currentAdjacentFilesMask
+	for rank := 0; rank < squareRank; rank++ {
+		blackMask &= chessEngine.ClearRankMasks[rank]
+	}
+	CheckBackwardPawnOnSquareMask[chessEngine.Black][square] = blackMask
+}
+
+// computePawnStopSquares stores the square directly in front of a pawn, or NoneSquare on the
+// last rank.
+func computePawnStopSquares(square uint8) {
+	PawnStopSquares[chessEngine.White][square], PawnStopSquares[chessEngine.Black][square] = chessEngine.NoneSquare, chessEngine.NoneSquare
+	if chessEngine.Rank(square) < 7 {
+		PawnStopSquares[chessEngine.White][square] = square + 8
+	}
+	if chessEngine.Rank(square) > 0 {
+		PawnStopSquares[chessEngine.Black][square] = square - 8
+	}
+}
+
+// computeSquareDistances stores the Chebyshev distance, the number of king moves, from the square
+// to every other square.
+func computeSquareDistances(square uint8) {
+	for otherSquare := uint8(0); otherSquare < 64; otherSquare++ {
+		fileDistance := int(chessEngine.File(square)) - int(chessEngine.File(otherSquare))
+		rankDistance := int(chessEngine.Rank(square)) - int(chessEngine.Rank(otherSquare))
+		if fileDistance < 0 {
+			fileDistance = -fileDistance
+		}
+		if rankDistance < 0 {
+			rankDistance = -rankDistance
+		}
+		SquareDistances[square][otherSquare] = uint8(fileDistance)
+		if rankDistance > fileDistance {
+			SquareDistances[square][otherSquare] = uint8(rankDistance)
+		}
+	}
+}
 func computeKingSafetyZonesOnSquareMask(square uint8) {
 	squareBitboard := chessEngine.BitboardForSquare[square]
 	var aroundKingZone chessEngine.Bitboard = ((squareBitboard & chessEngine.ClearFileMasks[chessEngine.FileH]) >> 1) | ((squareBitboard & (chessEngine.ClearFileMasks[chessEngine.FileG] & chessEngine.ClearFileMasks[chessEngine.FileH])) >> 2)
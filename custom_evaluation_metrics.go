@@ -17,192 +17,353 @@ var PiecePhaseIncrements = [6]int16{
 	QueenPhaseIncrement,
 }
 
-const (
-	MidGameIsolatedPawnPenalty int16 = 17
-	EndGameIsolatedPawnPenalty int16 = 6
-	MidGameDoubledPawnPenalty  int16 = 1
-	EndGameDoubledPawnPenalty  int16 = 16
-
-	MidGameKnightOnOutpostBonus int16 = 27
-	EndGameKnightOnOutpostBonus int16 = 18
-	MidGameBishopOnOutpostBonus int16 = 10
-	EndGameBishopOnOutpostBonus int16 = 14
-	MidGameBishopPairBonus      int16 = 30
-	EndgameBishopPairBonus      int16 = 45
-
-	EndGameBonusForRookOrQueenOnSeventhRank int16 = 45
-	MidGameRookOnOpenFileBonus              int16 = 23
-
-	MidGameTempoBonus int16 = 14
-)
+// EvaluationParameters holds every weight used by CustomEvaluator. Fields tagged with a uci range
+// are offered as spin options; square tables are left to evaluation profiles. Thresholds and scale
+// factors are tagged tune:"-" so that the Texel tuner leaves them alone.
+type EvaluationParameters struct {
+	MidGameIsolatedPawnPenalty int16 `uci:"0,100"`
+	EndGameIsolatedPawnPenalty int16 `uci:"0,100"`
+	MidGameDoubledPawnPenalty  int16 `uci:"0,100"`
+	EndGameDoubledPawnPenalty  int16 `uci:"0,100"`
+
+	MidGameBackwardPawnPenalty               int16 `uci:"0,100"`
+	EndGameBackwardPawnPenalty               int16 `uci:"0,100"`
+	MidGameBackwardPawnOnHalfOpenFilePenalty int16 `uci:"0,100"`
+	EndGameBackwardPawnOnHalfOpenFilePenalty int16 `uci:"0,100"`
+
+	MidGameConnectedPawnBonusPerRank [8]int16 `uci:"0,150"`
+	EndGameConnectedPawnBonusPerRank [8]int16 `uci:"0,150"`
+
+	EndGamePassedPawnEnemyKingDistanceBonus    int16 `uci:"0,30"`
+	EndGamePassedPawnOwnKingDistancePenalty    int16 `uci:"0,30"`
+	EndGamePassedPawnBlockadePenalty           int16 `uci:"0,50"`
+	EndGamePassedPawnAttackedStopSquarePenalty int16 `uci:"0,50"`
+	EndGamePassedPawnFreePathBonus             int16 `uci:"0,50"`
+	EndGamePassedPawnRookOrQueenBehindBonus    int16 `uci:"0,50"`
+	UnstoppablePassedPawnBonus                 int16 `uci:"0,1000"`
+
+	MidGameKnightOnOutpostBonus int16 `uci:"0,100"`
+	EndGameKnightOnOutpostBonus int16 `uci:"0,100"`
+	MidGameBishopOnOutpostBonus int16 `uci:"0,100"`
+	EndGameBishopOnOutpostBonus int16 `uci:"0,100"`
+	MidGameBishopPairBonus      int16 `uci:"0,150"`
+	EndgameBishopPairBonus      int16 `uci:"0,150"`
+
+	MidGameBadBishopPawnPenalty               int16 `uci:"0,50"`
+	EndGameBadBishopPawnPenalty               int16 `uci:"0,50"`
+	MidGameBadBishopBlockedCentralPawnPenalty int16 `uci:"0,50"`
+	EndGameBadBishopBlockedCentralPawnPenalty int16 `uci:"0,50"`
+
+	EndGameBonusForRookOrQueenOnSeventhRank  int16 `uci:"0,150"`
+	MidGameRookOnOpenFileBonus               int16 `uci:"0,150"`
+	MidGameRookOnSemiOpenFileBonus           int16 `uci:"0,100"`
+	EndGameRookOnSemiOpenFileBonus           int16 `uci:"0,100"`
+	MidGameRookBehindPassedPawnBonus         int16 `uci:"0,100"`
+	EndGameRookBehindPassedPawnBonus         int16 `uci:"0,100"`
+	MidGameRookInFrontOfOwnPassedPawnPenalty int16 `uci:"0,100"`
+	EndGameRookInFrontOfOwnPassedPawnPenalty int16 `uci:"0,100"`
+
+	MidGameThreatByPawnBonus         int16 `uci:"0,150"`
+	EndGameThreatByPawnBonus         int16 `uci:"0,150"`
+	MidGameThreatByMinorOnMajorBonus int16 `uci:"0,150"`
+	EndGameThreatByMinorOnMajorBonus int16 `uci:"0,150"`
+	MidGameThreatByRookOnQueenBonus  int16 `uci:"0,150"`
+	EndGameThreatByRookOnQueenBonus  int16 `uci:"0,150"`
+	MidGameHangingPieceBonus         int16 `uci:"0,150"`
+	EndGameHangingPieceBonus         int16 `uci:"0,150"`
+	MidGameSafePawnPushThreatBonus   int16 `uci:"0,100"`
+	EndGameSafePawnPushThreatBonus   int16 `uci:"0,100"`
+
+	MidGameTempoBonus int16 `uci:"0,100"`
+
+	MidGamePieceValues [6]int16 `uci:"0,2000"`
+	EndGamePieceValues [6]int16 `uci:"0,2000"`
+
+	MidGameMobilityScoresPerPiece [5]int16 `uci:"0,30"`
+	EndGameMobilityScoresPerPiece [5]int16 `uci:"0,30"`
+
+	OuterRingAttackScorePerPiece [5]int16 `uci:"0,20"`
+	InnerRingAttackScorePerPiece [5]int16 `uci:"0,20"`
+
+	SemiOpenFileBesideKingPenalty int16 `uci:"0,30"`
+
+	KingShieldPenaltyByRank           [8]int16 `uci:"0,20"`
+	KingPawnStormPenaltyByRank        [8]int16 `uci:"0,20"`
+	KingBlockedPawnStormPenaltyByRank [8]int16 `uci:"0,20"`
+
+	MidGameSpaceWeight int16 `uci:"0,40"`
+	SpaceMaximumPhase  int16 `uci:"0,24" tune:"-"`
+
+	MidGameTrappedBishopPenalty         int16 `uci:"0,300"`
+	EndGameTrappedBishopPenalty         int16 `uci:"0,300"`
+	MidGameRookShutInByKingPenalty      int16 `uci:"0,150"`
+	EndGameRookShutInByKingPenalty      int16 `uci:"0,150"`
+	MidGameTrappedKnightInCornerPenalty int16 `uci:"0,300"`
+	EndGameTrappedKnightInCornerPenalty int16 `uci:"0,300"`
+	MidGameImmobileRookOrQueenPenalty   int16 `uci:"0,150"`
+	EndGameImmobileRookOrQueenPenalty   int16 `uci:"0,150"`
+
+	OppositeBishopsScale                   int16 `uci:"0,64" tune:"-"`
+	OppositeBishopsWithPiecesScale         int16 `uci:"0,64" tune:"-"`
+	OppositeBishopsScalePerPawnDifference  int16 `uci:"0,32" tune:"-"`
+	OppositeBishopsPassersOnBothWingsScale int16 `uci:"0,64" tune:"-"`
+
+	FiftyMoveDampingStart int16 `uci:"0,99" tune:"-"`
+
+	MidGamePieceSquareTables [6][64]int16
+	EndGamePieceSquareTables [6][64]int16
+
+	MidGamePassedPawnSquareTables [64]int16
+	EndGamePassedPawnSquareTables [64]int16
+}
+
+var DefaultEvaluationParameters = EvaluationParameters{
+	MidGameIsolatedPawnPenalty: 17,
+	EndGameIsolatedPawnPenalty: 6,
+	MidGameDoubledPawnPenalty:  1,
+	EndGameDoubledPawnPenalty:  16,
+
+	MidGameBackwardPawnPenalty:               8,
+	EndGameBackwardPawnPenalty:               12,
+	MidGameBackwardPawnOnHalfOpenFilePenalty: 10,
+	EndGameBackwardPawnOnHalfOpenFilePenalty: 4,
+
+	MidGameConnectedPawnBonusPerRank: [8]int16{0, 3, 4, 6, 14, 24, 43, 0},
+	EndGameConnectedPawnBonusPerRank: [8]int16{0, 1, 2, 4, 10, 20, 40, 0},
+
+	EndGamePassedPawnEnemyKingDistanceBonus:    5,
+	EndGamePassedPawnOwnKingDistancePenalty:    2,
+	EndGamePassedPawnBlockadePenalty:           6,
+	EndGamePassedPawnAttackedStopSquarePenalty: 3,
+	EndGamePassedPawnFreePathBonus:             8,
+	EndGamePassedPawnRookOrQueenBehindBonus:    3,
+	UnstoppablePassedPawnBonus:                 650,
+
+	MidGameKnightOnOutpostBonus: 27,
+	EndGameKnightOnOutpostBonus: 18,
+	MidGameBishopOnOutpostBonus: 10,
+	EndGameBishopOnOutpostBonus: 14,
+	MidGameBishopPairBonus:      30,
+	EndgameBishopPairBonus:      45,
+
+	MidGameBadBishopPawnPenalty:               2,
+	EndGameBadBishopPawnPenalty:               5,
+	MidGameBadBishopBlockedCentralPawnPenalty: 4,
+	EndGameBadBishopBlockedCentralPawnPenalty: 3,
 
-var MidGamePieceValues = [6]int16{84, 333, 346, 441, 921}
-var EndGamePieceValues = [6]int16{106, 244, 268, 478, 886}
+	EndGameBonusForRookOrQueenOnSeventhRank:  45,
+	MidGameRookOnOpenFileBonus:               23,
+	MidGameRookOnSemiOpenFileBonus:           10,
+	EndGameRookOnSemiOpenFileBonus:           6,
+	MidGameRookBehindPassedPawnBonus:         4,
+	EndGameRookBehindPassedPawnBonus:         18,
+	MidGameRookInFrontOfOwnPassedPawnPenalty: 4,
+	EndGameRookInFrontOfOwnPassedPawnPenalty: 14,
 
-var MidGameMobilityScoresPerPiece = [5]int16{0, 5, 3, 3, 0}
-var EndGameMobilityScoresPerPiece = [5]int16{0, 2, 3, 2, 6}
+	MidGameThreatByPawnBonus:         45,
+	EndGameThreatByPawnBonus:         35,
+	MidGameThreatByMinorOnMajorBonus: 30,
+	EndGameThreatByMinorOnMajorBonus: 25,
+	MidGameThreatByRookOnQueenBonus:  30,
+	EndGameThreatByRookOnQueenBonus:  20,
+	MidGameHangingPieceBonus:         20,
+	EndGameHangingPieceBonus:         15,
+	MidGameSafePawnPushThreatBonus:   12,
+	EndGameSafePawnPushThreatBonus:   10,
 
-var OuterRingAttackScorePerPiece = [5]int16{0, 1, 0, 1, 1}
-var InnerRingAttackScorePerPiece = [5]int16{0, 3, 4, 3, 2}
+	MidGameTempoBonus: 14,
 
-var SemiOpenFileBesideKingPenalty int16 = 4
+	MidGamePieceValues: [6]int16{84, 333, 346, 441, 921},
+	EndGamePieceValues: [6]int16{106, 244, 268, 478, 886},
 
-var MidGamePieceSquareTables = [6][64]int16{
-	{
-		// MG Pawn PST
+	MidGameMobilityScoresPerPiece: [5]int16{0, 5, 3, 3, 0},
+	EndGameMobilityScoresPerPiece: [5]int16{0, 2, 3, 2, 6},
+
+	OuterRingAttackScorePerPiece: [5]int16{0, 1, 0, 1, 1},
+	InnerRingAttackScorePerPiece: [5]int16{0, 3, 4, 3, 2},
+
+	SemiOpenFileBesideKingPenalty: 4,
+
+	KingShieldPenaltyByRank:           [8]int16{4, 0, 1, 2, 3, 4, 4, 4},
+	KingPawnStormPenaltyByRank:        [8]int16{0, 0, 4, 2, 1, 0, 0, 0},
+	KingBlockedPawnStormPenaltyByRank: [8]int16{0, 0, 1, 1, 0, 0, 0, 0},
+
+	MidGameSpaceWeight: 4,
+	SpaceMaximumPhase:  10,
+
+	MidGameTrappedBishopPenalty:         120,
+	EndGameTrappedBishopPenalty:         100,
+	MidGameRookShutInByKingPenalty:      45,
+	EndGameRookShutInByKingPenalty:      10,
+	MidGameTrappedKnightInCornerPenalty: 80,
+	EndGameTrappedKnightInCornerPenalty: 60,
+	MidGameImmobileRookOrQueenPenalty:   30,
+	EndGameImmobileRookOrQueenPenalty:   25,
+
+	OppositeBishopsScale:                   16,
+	OppositeBishopsWithPiecesScale:         44,
+	OppositeBishopsScalePerPawnDifference:  6,
+	OppositeBishopsPassersOnBothWingsScale: 16,
+
+	FiftyMoveDampingStart: 20,
+
+	MidGamePieceSquareTables: [6][64]int16{
+		{
+			// MG Pawn PST
+			0, 0, 0, 0, 0, 0, 0, 0,
+			45, 52, 42, 43, 28, 34, 19, 9,
+			-14, -3, 7, 14, 35, 50, 15, -6,
+			-27, -6, -8, 13, 16, 4, -3, -25,
+			-32, -28, -7, 5, 7, -1, -15, -30,
+			-29, -25, -12, -12, -1, -5, 6, -17,
+			-34, -23, -27, -18, -14, 10, 13, -22,
+			0, 0, 0, 0, 0, 0, 0, 0,
+		},
+		{
+			// MG Knight PST
+			-43, -11, -8, -5, 1, -20, -4, -22,
+			-31, -22, 19, 7, 5, 13, -8, -11,
+			-21, 21, 8, 16, 36, 33, 19, 6,
+			-6, 2, 0, 23, 8, 27, 4, 14,
+			-3, 10, 12, 8, 16, 10, 19, 1,
+			-19, -4, 3, 7, 22, 12, 15, -11,
+			-21, -20, -9, 8, 9, 11, -5, 0,
+			-19, -13, -20, -14, -2, 3, -11, -8,
+		},
+		{
+			// MG Bishop PST
+			-13, 0, -17, -8, -7, -5, -2, -3,
+			-21, 0, -16, -10, 4, 1, -6, -41,
+			-23, 6, 10, 8, 8, 26, 0, -10,
+			-15, -4, 2, 22, 9, 10, -1, -16,
+			0, 10, -2, 15, 17, -7, -1, 13,
+			-2, 16, 13, 0, 5, 16, 14, 0,
+			8, 11, 12, 3, 11, 23, 27, 3,
+			-26, 3, -3, -1, 10, -5, -7, -15,
+		},
+		{
+			// MG Rook PST
+			3, 1, 0, 7, 7, -1, 0, 0,
+			-6, -9, 7, 7, 7, 5, -4, -1,
+			-12, 11, 0, 17, -2, 12, 23, -1,
+			-17, -9, 4, 0, 3, 15, -1, -2,
+			-24, -16, -16, -4, -1, -14, 2, -20,
+			-30, -15, -6, -3, 0, 2, 2, -15,
+			-25, -6, -6, 5, 8, 6, 8, -46,
+			-3, 1, 6, 15, 17, 14, -13, -2,
+		},
+		{
+			// MG Queen PST
+			-10, 0, 0, 0, 10, 9, 5, 7,
+			-19, -35, -5, 2, -9, 7, 1, 15,
+			-10, -7, -4, -9, 15, 29, 24, 22,
+			-14, -14, -15, -11, -1, -5, 3, -6,
+			-8, -20, -8, -5, -4, -2, 2, -2,
+			-13, 5, 2, 1, -1, 8, 4, 2,
+			-20, 0, 10, 16, 16, 16, -6, 6,
+			-3, -1, 7, 19, 5, -10, -9, -17,
+		},
+		{
+			// MG King PST
+			-3, 0, 2, 0, 0, 0, 1, -1,
+			1, 4, 0, 7, 4, 2, 3, -2,
+			2, 4, 7, 4, 4, 14, 12, 0,
+			0, 2, 6, 0, 0, 2, 6, -9,
+			-8, 5, 0, -8, -10, -10, -9, -23,
+			-3, 5, 1, -8, -12, -12, 8, -24,
+			6, 13, 0, -40, -23, -1, 25, 19,
+			-28, 29, 17, -53, 2, -25, 34, 15,
+		},
+	},
+
+	EndGamePieceSquareTables: [6][64]int16{
+		{
+			// EG Pawn PST
+			0, 0, 0, 0, 0, 0, 0, 0,
+			77, 74, 63, 53, 59, 60, 72, 77,
+			17, 11, 11, 11, 11, -6, 14, 8,
+			-3, -14, -18, -31, -29, -25, -20, -18,
+			-12, -14, -24, -31, -29, -28, -27, -28,
+			-22, -20, -25, -20, -21, -24, -34, -34,
+			-16, -22, -11, -19, -13, -23, -32, -34,
+			0, 0, 0, 0, 0, 0, 0, 0,
+		},
+		{
+			// EG Knight PST
+			-36, -16, -7, -14, -4, -20, -20, -29,
+			-17, 2, -7, 14, 2, -7, -9, -19,
+			-13, -7, 14, 12, 4, 6, 0, -13,
+			-5, 8, 24, 18, 22, 15, 11, -4,
+			-3, 4, 20, 30, 22, 25, 15, -2,
+			-7, 1, 3, 19, 10, -2, -4, -4,
+			-10, -2, -1, 0, 6, -8, -3, -13,
+			-12, -28, -8, 1, -5, -12, -27, -12,
+		},
+		{
+			// EG Bishop PST
+			-9, -5, -9, -5, -2, -4, -5, -8,
+			0, 2, 8, -7, 1, 0, -2, -8,
+			8, 0, 0, 1, 0, 1, 5, 6,
+			0, 7, 7, 8, 3, 5, 2, 6,
+			-1, 0, 12, 8, 0, 6, 0, -5,
+			0, 0, 3, 6, 8, -1, 0, -1,
+			-6, -12, -7, 0, 0, -8, -9, -13,
+			-11, 0, -6, 0, -3, -4, -5, -9,
+		},
+		{
+			// EG Rook PST
+			8, 9, 11, 13, 13, 12, 13, 9,
+			3, 5, 1, 0, -1, 0, 6, 2,
+			9, 5, 7, 2, 2, 1, 0, 0,
+			3, 3, 6, 0, 0, 0, 0, 4,
+			5, 4, 9, 0, -3, -2, -6, -2,
+			0, 0, -6, -5, -9, -14, -7, -12,
+			-2, -5, -1, -7, -9, -11, -13, -1,
+			-7, -3, 0, -8, -13, -12, -4, -24,
+		},
+		{
+			// EG Queen PST
+			-12, 4, 8, 4, 10, 9, 3, 6,
+			-17, -7, -1, 7, 3, 6, 1, 0,
+			-5, -1, -4, 12, 14, 20, 12, 14,
+			-2, 2, 2, 9, 13, 7, 18, 22,
+			-9, 3, 1, 15, 5, 10, 12, 10,
+			-6, -20, 0, -15, 0, -1, 10, 7,
+			-6, -14, -31, -27, -19, -12, -11, -4,
+			-12, -22, -19, -30, -8, -13, -6, -15,
+		},
+		{
+			// EG King PST
+			-15, -11, -11, -6, -2, 3, 4, -9,
+			-9, 14, 11, 13, 13, 28, 19, 1,
+			-1, 18, 19, 15, 16, 35, 34, 4,
+			-12, 14, 21, 25, 19, 25, 18, -5,
+			-23, -6, 14, 21, 20, 18, 5, -16,
+			-21, -6, 5, 13, 15, 9, -2, -12,
+			-27, -10, 2, 9, 9, 1, -12, -26,
+			-43, -34, -20, -5, -26, -9, -35, -55,
+		},
+	},
+
+	MidGamePassedPawnSquareTables: [64]int16{
 		0, 0, 0, 0, 0, 0, 0, 0,
 		45, 52, 42, 43, 28, 34, 19, 9,
-		-14, -3, 7, 14, 35, 50, 15, -6,
-		-27, -6, -8, 13, 16, 4, -3, -25,
-		-32, -28, -7, 5, 7, -1, -15, -30,
-		-29, -25, -12, -12, -1, -5, 6, -17,
-		-34, -23, -27, -18, -14, 10, 13, -22,
+		48, 43, 43, 30, 24, 31, 12, 2,
+		28, 17, 13, 10, 10, 19, 6, 1,
+		14, 0, -9, -7, -13, -7, 9, 16,
+		5, 3, -3, -14, -3, 10, 13, 19,
+		8, 9, 2, -8, -3, 8, 16, 9,
 		0, 0, 0, 0, 0, 0, 0, 0,
 	},
-	{
-		// MG Knight PST
-		-43, -11, -8, -5, 1, -20, -4, -22,
-		-31, -22, 19, 7, 5, 13, -8, -11,
-		-21, 21, 8, 16, 36, 33, 19, 6,
-		-6, 2, 0, 23, 8, 27, 4, 14,
-		-3, 10, 12, 8, 16, 10, 19, 1,
-		-19, -4, 3, 7, 22, 12, 15, -11,
-		-21, -20, -9, 8, 9, 11, -5, 0,
-		-19, -13, -20, -14, -2, 3, -11, -8,
-	},
-	{
-		// MG Bishop PST
-		-13, 0, -17, -8, -7, -5, -2, -3,
-		-21, 0, -16, -10, 4, 1, -6, -41,
-		-23, 6, 10, 8, 8, 26, 0, -10,
-		-15, -4, 2, 22, 9, 10, -1, -16,
-		0, 10, -2, 15, 17, -7, -1, 13,
-		-2, 16, 13, 0, 5, 16, 14, 0,
-		8, 11, 12, 3, 11, 23, 27, 3,
-		-26, 3, -3, -1, 10, -5, -7, -15,
-	},
-	{
-		// MG Rook PST
-		3, 1, 0, 7, 7, -1, 0, 0,
-		-6, -9, 7, 7, 7, 5, -4, -1,
-		-12, 11, 0, 17, -2, 12, 23, -1,
-		-17, -9, 4, 0, 3, 15, -1, -2,
-		-24, -16, -16, -4, -1, -14, 2, -20,
-		-30, -15, -6, -3, 0, 2, 2, -15,
-		-25, -6, -6, 5, 8, 6, 8, -46,
-		-3, 1, 6, 15, 17, 14, -13, -2,
-	},
-	{
-		// MG Queen PST
-		-10, 0, 0, 0, 10, 9, 5, 7,
-		-19, -35, -5, 2, -9, 7, 1, 15,
-		-10, -7, -4, -9, 15, 29, 24, 22,
-		-14, -14, -15, -11, -1, -5, 3, -6,
-		-8, -20, -8, -5, -4, -2, 2, -2,
-		-13, 5, 2, 1, -1, 8, 4, 2,
-		-20, 0, 10, 16, 16, 16, -6, 6,
-		-3, -1, 7, 19, 5, -10, -9, -17,
-	},
-	{
-		// MG King PST
-		-3, 0, 2, 0, 0, 0, 1, -1,
-		1, 4, 0, 7, 4, 2, 3, -2,
-		2, 4, 7, 4, 4, 14, 12, 0,
-		0, 2, 6, 0, 0, 2, 6, -9,
-		-8, 5, 0, -8, -10, -10, -9, -23,
-		-3, 5, 1, -8, -12, -12, 8, -24,
-		6, 13, 0, -40, -23, -1, 25, 19,
-		-28, 29, 17, -53, 2, -25, 34, 15,
-	},
-}
 
-var EndGamePieceSquareTables = [6][64]int16{
-	{
-		// EG Pawn PST
+	EndGamePassedPawnSquareTables: [64]int16{
 		0, 0, 0, 0, 0, 0, 0, 0,
 		77, 74, 63, 53, 59, 60, 72, 77,
-		17, 11, 11, 11, 11, -6, 14, 8,
-		-3, -14, -18, -31, -29, -25, -20, -18,
-		-12, -14, -24, -31, -29, -28, -27, -28,
-		-22, -20, -25, -20, -21, -24, -34, -34,
-		-16, -22, -11, -19, -13, -23, -32, -34,
+		91, 83, 66, 40, 30, 61, 67, 84,
+		55, 52, 42, 35, 30, 34, 56, 52,
+		29, 26, 21, 18, 17, 19, 34, 30,
+		8, 6, 5, 1, 1, -1, 14, 7,
+		2, 3, -4, 0, -2, -1, 7, 6,
 		0, 0, 0, 0, 0, 0, 0, 0,
 	},
-	{
-		// EG Knight PST
-		-36, -16, -7, -14, -4, -20, -20, -29,
-		-17, 2, -7, 14, 2, -7, -9, -19,
-		-13, -7, 14, 12, 4, 6, 0, -13,
-		-5, 8, 24, 18, 22, 15, 11, -4,
-		-3, 4, 20, 30, 22, 25, 15, -2,
-		-7, 1, 3, 19, 10, -2, -4, -4,
-		-10, -2, -1, 0, 6, -8, -3, -13,
-		-12, -28, -8, 1, -5, -12, -27, -12,
-	},
-	{
-		// EG Bishop PST
-		-9, -5, -9, -5, -2, -4, -5, -8,
-		0, 2, 8, -7, 1, 0, -2, -8,
-		8, 0, 0, 1, 0, 1, 5, 6,
-		0, 7, 7, 8, 3, 5, 2, 6,
-		-1, 0, 12, 8, 0, 6, 0, -5,
-		0, 0, 3, 6, 8, -1, 0, -1,
-		-6, -12, -7, 0, 0, -8, -9, -13,
-		-11, 0, -6, 0, -3, -4, -5, -9,
-	},
-	{
-		// EG Rook PST
-		8, 9, 11, 13, 13, 12, 13, 9,
-		3, 5, 1, 0, -1, 0, 6, 2,
-		9, 5, 7, 2, 2, 1, 0, 0,
-		3, 3, 6, 0, 0, 0, 0, 4,
-		5, 4, 9, 0, -3, -2, -6, -2,
-		0, 0, -6, -5, -9, -14, -7, -12,
-		-2, -5, -1, -7, -9, -11, -13, -1,
-		-7, -3, 0, -8, -13, -12, -4, -24,
-	},
-	{
-		// EG Queen PST
-		-12, 4, 8, 4, 10, 9, 3, 6,
-		-17, -7, -1, 7, 3, 6, 1, 0,
-		-5, -1, -4, 12, 14, 20, 12, 14,
-		-2, 2, 2, 9, 13, 7, 18, 22,
-		-9, 3, 1, 15, 5, 10, 12, 10,
-		-6, -20, 0, -15, 0, -1, 10, 7,
-		-6, -14, -31, -27, -19, -12, -11, -4,
-		-12, -22, -19, -30, -8, -13, -6, -15,
-	},
-	{
-		// EG King PST
-		-15, -11, -11, -6, -2, 3, 4, -9,
-		-9, 14, 11, 13, 13, 28, 19, 1,
-		-1, 18, 19, 15, 16, 35, 34, 4,
-		-12, 14, 21, 25, 19, 25, 18, -5,
-		-23, -6, 14, 21, 20, 18, 5, -16,
-		-21, -6, 5, 13, 15, 9, -2, -12,
-		-27, -10, 2, 9, 9, 1, -12, -26,
-		-43, -34, -20, -5, -26, -9, -35, -55,
-	},
-}
-
-var MidGamePassedPawnSquareTables = [64]int16{
-	0, 0, 0, 0, 0, 0, 0, 0,
-	45, 52, 42, 43, 28, 34, 19, 9,
-	48, 43, 43, 30, 24, 31, 12, 2,
-	28, 17, 13, 10, 10, 19, 6, 1,
-	14, 0, -9, -7, -13, -7, 9, 16,
-	5, 3, -3, -14, -3, 10, 13, 19,
-	8, 9, 2, -8, -3, 8, 16, 9,
-	0, 0, 0, 0, 0, 0, 0, 0,
-}
-
-var EndGamePassedPawnSquareTables = [64]int16{
-	0, 0, 0, 0, 0, 0, 0, 0,
-	77, 74, 63, 53, 59, 60, 72, 77,
-	91, 83, 66, 40, 30, 61, 67, 84,
-	55, 52, 42, 35, 30, 34, 56, 52,
-	29, 26, 21, 18, 17, 19, 34, 30,
-	8, 6, 5, 1, 1, -1, 14, 7,
-	2, 3, -4, 0, -2, -1, 7, 6,
-	0, 0, 0, 0, 0, 0, 0, 0,
 }
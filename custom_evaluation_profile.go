@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// LoadEvaluationParameters reads a JSON evaluation profile. The profile is applied on top of
+// DefaultEvaluationParameters, so a profile only has to list the weights it changes.
+func LoadEvaluationParameters(profilePath string) (*EvaluationParameters, error) {
+	profileContent, err := os.ReadFile(profilePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading evaluation profile: %w", err)
+	}
+
+	parameters := DefaultEvaluationParameters
+	if err := parameters.applyProfile(profileContent); err != nil {
+		return nil, fmt.Errorf("evaluation profile %s: %w", profilePath, err)
+	}
+	if err := parameters.checkRanges(); err != nil {
+		return nil, fmt.Errorf("evaluation profile %s: %w", profilePath, err)
+	}
+	return &parameters, nil
+}
+
+// SaveEvaluationParameters writes every weight of the given parameters as a JSON profile
+// that LoadEvaluationParameters can read back.
+func SaveEvaluationParameters(profilePath string, parameters *EvaluationParameters) error {
+	var profileBuilder strings.Builder
+	parametersValue := reflect.ValueOf(parameters).Elem()
+	parametersType := parametersValue.Type()
+
+	profileBuilder.WriteString("{\n")
+	for fieldIndex := 0; fieldIndex < parametersValue.NumField(); fieldIndex++ {
+		profileBuilder.WriteString(fmt.Sprintf("\t%q: ", parametersType.Field(fieldIndex).Name))
+		writeProfileValue(&profileBuilder, parametersValue.Field(fieldIndex), 1)
+		if fieldIndex != parametersValue.NumField()-1 {
+			profileBuilder.WriteString(",")
+		}
+		profileBuilder.WriteString("\n")
+	}
+	profileBuilder.WriteString("}\n")
+
+	return os.WriteFile(profilePath, []byte(profileBuilder.String()), 0644)
+}
+
+// EvaluationWeight is a single named entry of EvaluationParameters, e.g. "MidGameTempoBonus" or
+// "MidGamePieceSquareTables[1][27]". UciRange is the field's uci tag, empty for square tables.
+// IsTunable is false for fields tagged tune:"-".
+type EvaluationWeight struct {
+	Name      string
+	Value     *int16
+	UciRange  string
+	IsTunable bool
+}
+
+// Weights flattens the parameters into their individual weights, in field order.
+func (parameters *EvaluationParameters) Weights() []EvaluationWeight {
+	var weights []EvaluationWeight
+	parametersValue := reflect.ValueOf(parameters).Elem()
+	parametersType := parametersValue.Type()
+	for fieldIndex := 0; fieldIndex < parametersValue.NumField(); fieldIndex++ {
+		field := parametersType.Field(fieldIndex)
+		weights = appendWeights(weights, parametersValue.Field(fieldIndex), field.Name, field.Tag.Get("uci"), field.Tag.Get("tune") != "-")
+	}
+	return weights
+}
+
+// Range returns the bounds of the weight's uci tag, or false for weights without one.
+func (weight EvaluationWeight) Range() (int16, int16, bool) {
+	minText, maxText, hasRange := strings.Cut(weight.UciRange, ",")
+	if !hasRange {
+		return 0, 0, false
+	}
+	minValue, minErr := strconv.ParseInt(minText, 10, 16)
+	maxValue, maxErr := strconv.ParseInt(maxText, 10, 16)
+	if minErr != nil || maxErr != nil {
+		panic(fmt.Sprintf("%s: malformed uci range %q", weight.Name, weight.UciRange))
+	}
+	return int16(minValue), int16(maxValue), true
+}
+
+// checkRanges fails on the first weight outside the range its uci tag declares.
+func (parameters *EvaluationParameters) checkRanges() error {
+	for _, weight := range parameters.Weights() {
+		if minValue, maxValue, hasRange := weight.Range(); hasRange && (*weight.Value < minValue || *weight.Value > maxValue) {
+			return fmt.Errorf("%s: %d is outside the range %d to %d", weight.Name, *weight.Value, minValue, maxValue)
+		}
+	}
+	return nil
+}
+
+func appendWeights(weights []EvaluationWeight, value reflect.Value, weightName string, uciRange string, isTunable bool) []EvaluationWeight {
+	if value.Kind() != reflect.Array {
+		return append(weights, EvaluationWeight{
+			Name:      weightName,
+			Value:     value.Addr().Interface().(*int16),
+			UciRange:  uciRange,
+			IsTunable: isTunable,
+		})
+	}
+	for entryIndex := 0; entryIndex < value.Len(); entryIndex++ {
+		weights = appendWeights(weights, value.Index(entryIndex), fmt.Sprintf("%s[%d]", weightName, entryIndex), uciRange, isTunable)
+	}
+	return weights
+}
+
+func (parameters *EvaluationParameters) applyProfile(profileContent []byte) error {
+	var profileEntries map[string]json.RawMessage
+	if err := json.Unmarshal(profileContent, &profileEntries); err != nil {
+		return err
+	}
+
+	parametersValue := reflect.ValueOf(parameters).Elem()
+	for parameterName, rawParameterValue := range profileEntries {
+		field := parametersValue.FieldByName(parameterName)
+		if !field.IsValid() {
+			return fmt.Errorf("unknown evaluation parameter %q", parameterName)
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(rawParameterValue))
+		decoder.UseNumber()
+		var parameterValue interface{}
+		if err := decoder.Decode(&parameterValue); err != nil {
+			return fmt.Errorf("%s: %w", parameterName, err)
+		}
+		if err := assignProfileValue(field, parameterValue, parameterName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func assignProfileValue(field reflect.Value, parameterValue interface{}, parameterName string) error {
+	switch field.Kind() {
+	case reflect.Array:
+		tableEntries, isTable := parameterValue.([]interface{})
+		if !isTable {
+			return fmt.Errorf("%s: expected a table of %d entries", parameterName, field.Len())
+		}
+		if len(tableEntries) != field.Len() {
+			return fmt.Errorf("%s: expected %d entries, got %d", parameterName, field.Len(), len(tableEntries))
+		}
+		for entryIndex, tableEntry := range tableEntries {
+			if err := assignProfileValue(field.Index(entryIndex), tableEntry, fmt.Sprintf("%s[%d]", parameterName, entryIndex)); err != nil {
+				return err
+			}
+		}
+	case reflect.Int16:
+		number, isNumber := parameterValue.(json.Number)
+		if !isNumber {
+			return fmt.Errorf("%s: expected an integer", parameterName)
+		}
+		weight, err := strconv.ParseInt(number.String(), 10, 16)
+		if err != nil {
+			return fmt.Errorf("%s: %s is not a 16-bit integer", parameterName, number)
+		}
+		field.SetInt(weight)
+	default:
+		return fmt.Errorf("%s: unsupported parameter type %s", parameterName, field.Type())
+	}
+	return nil
+}
+
+func writeProfileValue(profileBuilder *strings.Builder, value reflect.Value, indentation int) {
+	if value.Kind() != reflect.Array {
+		profileBuilder.WriteString(strconv.FormatInt(value.Int(), 10))
+		return
+	}
+
+	if value.Type().Elem().Kind() == reflect.Array {
+		profileBuilder.WriteString("[\n")
+		for entryIndex := 0; entryIndex < value.Len(); entryIndex++ {
+			profileBuilder.WriteString(strings.Repeat("\t", indentation+1))
+			writeProfileValue(profileBuilder, value.Index(entryIndex), indentation+1)
+			if entryIndex != value.Len()-1 {
+				profileBuilder.WriteString(",")
+			}
+			profileBuilder.WriteString("\n")
+		}
+		profileBuilder.WriteString(strings.Repeat("\t", indentation) + "]")
+		return
+	}
+
+	// Square tables are written one board rank per line, everything else on a single line.
+	entriesPerLine := value.Len()
+	if entriesPerLine == 64 {
+		entriesPerLine = 8
+	}
+
+	profileBuilder.WriteString("[")
+	for entryIndex := 0; entryIndex < value.Len(); entryIndex++ {
+		if entriesPerLine != value.Len() && entryIndex%entriesPerLine == 0 {
+			profileBuilder.WriteString("\n" + strings.Repeat("\t", indentation+1))
+		} else if entryIndex != 0 {
+			profileBuilder.WriteString(" ")
+		}
+		profileBuilder.WriteString(strconv.FormatInt(value.Index(entryIndex).Int(), 10))
+		if entryIndex != value.Len()-1 {
+			profileBuilder.WriteString(",")
+		}
+	}
+	if entriesPerLine != value.Len() {
+		profileBuilder.WriteString("\n" + strings.Repeat("\t", indentation))
+	}
+	profileBuilder.WriteString("]")
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+const defaultProfilePath = "profiles/default.json"
+
+// TestDefaultProfileMatchesDefaultParameters keeps profiles/default.json in step with
+// DefaultEvaluationParameters: the file must list every parameter, and loading it must give the
+// built-in weights. Regenerate it with -dump-profile after changing a weight.
+func TestDefaultProfileMatchesDefaultParameters(t *testing.T) {
+	profileContent, err := os.ReadFile(defaultProfilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var profileEntries map[string]json.RawMessage
+	if err := json.Unmarshal(profileContent, &profileEntries); err != nil {
+		t.Fatal(err)
+	}
+	parametersType := reflect.TypeOf(EvaluationParameters{})
+	for fieldIndex := 0; fieldIndex < parametersType.NumField(); fieldIndex++ {
+		if parameterName := parametersType.Field(fieldIndex).Name; profileEntries[parameterName] == nil {
+			t.Errorf("%s does not list %s", defaultProfilePath, parameterName)
+		}
+	}
+
+	loadedParameters, err := LoadEvaluationParameters(defaultProfilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaultParameters := DefaultEvaluationParameters
+	defaultWeights := defaultParameters.Weights()
+	for weightIndex, loadedWeight := range loadedParameters.Weights() {
+		if *loadedWeight.Value != *defaultWeights[weightIndex].Value {
+			t.Errorf("%s is %d in %s and %d in DefaultEvaluationParameters", loadedWeight.Name, *loadedWeight.Value, defaultProfilePath, *defaultWeights[weightIndex].Value)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/A7mad-2000as/GoFish/chessEngine"
+)
+
+const (
+	MaterialAndPieceSquaresTerm uint8 = iota
+	PawnStructureTerm
+	PassedPawnTerm
+	OutpostTerm
+	MobilityTerm
+	KingSafetyTerm
+	BishopPairTerm
+	BadBishopTerm
+	RookAndQueenPlacementTerm
+	ThreatTerm
+	SpaceTerm
+	TrappedPiecesTerm
+	TempoTerm
+	NumberOfEvaluationTerms
+)
+
+var EvaluationTermNames = [NumberOfEvaluationTerms]string{
+	"Material + PST",
+	"Pawn structure",
+	"Passed pawns",
+	"Outposts",
+	"Mobility",
+	"King attack",
+	"Bishop pair",
+	"Bad bishops",
+	"Rooks and queens",
+	"Threats",
+	"Space",
+	"Trapped pieces",
+	"Tempo",
+}
+
+// EvaluationTrace breaks a CustomEvaluator score down into its terms. Term scores are per color,
+// while BlendedScore and FinalScore are from the side to move's point of view.
+type EvaluationTrace struct {
+	SideToMove        uint8
+	MidgameScores     [NumberOfEvaluationTerms][2]int16
+	EndgameScores     [NumberOfEvaluationTerms][2]int16
+	Phase             int16
+	ScaledPhase       int16
+	EndgameScale      int16
+	BlendedScore      int16
+	FinalScore        int16
+	MaterialSignature string
+	MaterialScale     int16
+	KnownEndgame      string
+	FiftyMoveClock    uint8
+	UndampedScore     int16
+}
+
+func (trace EvaluationTrace) String() string {
+	var traceBuilder strings.Builder
+	rowFormat := "%-18s | %6v %6v | %6v %6v | %6v %6v\n"
+	separator := strings.Repeat("-", 18) + "-+-" + strings.Repeat("-", 13) + "-+-" + strings.Repeat("-", 13) + "-+-" + strings.Repeat("-", 13) + "\n"
+
+	traceBuilder.WriteString(fmt.Sprintf("%-18s | %13s | %13s | %13s\n", "Term", "White", "Black", "Total"))
+	traceBuilder.WriteString(fmt.Sprintf(rowFormat, "", "MG", "EG", "MG", "EG", "MG", "EG"))
+	traceBuilder.WriteString(separator)
+
+	var midgameTotals, endgameTotals [2]int16
+	for term := uint8(0); term < NumberOfEvaluationTerms; term++ {
+		midgameScores, endgameScores := trace.MidgameScores[term], trace.EndgameScores[term]
+		traceBuilder.WriteString(fmt.Sprintf(rowFormat, EvaluationTermNames[term],
+			midgameScores[chessEngine.White], endgameScores[chessEngine.White],
+			midgameScores[chessEngine.Black], endgameScores[chessEngine.Black],
+			midgameScores[chessEngine.White]-midgameScores[chessEngine.Black], endgameScores[chessEngine.White]-endgameScores[chessEngine.Black]))
+		for color := chessEngine.Black; color <= chessEngine.White; color++ {
+			midgameTotals[color] += midgameScores[color]
+			endgameTotals[color] += endgameScores[color]
+		}
+	}
+
+	traceBuilder.WriteString(separator)
+	traceBuilder.WriteString(fmt.Sprintf(rowFormat, "Total",
+		midgameTotals[chessEngine.White], endgameTotals[chessEngine.White],
+		midgameTotals[chessEngine.Black], endgameTotals[chessEngine.Black],
+		midgameTotals[chessEngine.White]-midgameTotals[chessEngine.Black], endgameTotals[chessEngine.White]-endgameTotals[chessEngine.Black]))
+	traceBuilder.WriteString("\n")
+
+	sideToMoveSign := int16(1)
+	if trace.SideToMove == chessEngine.Black {
+		sideToMoveSign = -1
+	}
+	traceBuilder.WriteString(fmt.Sprintf("Phase: %d/%d (%d/256 endgame weight)\n", trace.Phase, TotalPhaseIncrement, trace.ScaledPhase))
+	traceBuilder.WriteString(fmt.Sprintf("Endgame scale: %d/%d\n", trace.EndgameScale, NormalScaleFactor))
+	traceBuilder.WriteString(fmt.Sprintf("Phase blend (white): %d\n", trace.BlendedScore*sideToMoveSign))
+	traceBuilder.WriteString(fmt.Sprintf("Material scale (%s): %d/%d\n", trace.MaterialSignature, trace.MaterialScale, NormalScaleFactor))
+	if trace.KnownEndgame != "" {
+		traceBuilder.WriteString(fmt.Sprintf("Known endgame: %s, the terms above are not used\n", trace.KnownEndgame))
+	}
+	if int16(trace.FiftyMoveClock) >= fiftyMoveRulePlies {
+		traceBuilder.WriteString(fmt.Sprintf("Fifty-move clock: %d plies, drawn by the fifty-move rule\n", trace.FiftyMoveClock))
+	} else if trace.UndampedScore != trace.FinalScore {
+		traceBuilder.WriteString(fmt.Sprintf("Fifty-move clock: %d plies, side-to-move score damped from %d\n", trace.FiftyMoveClock, trace.UndampedScore))
+	}
+	traceBuilder.WriteString(fmt.Sprintf("Final evaluation (white): %d\n", trace.FinalScore*sideToMoveSign))
+	traceBuilder.WriteString(fmt.Sprintf("Final evaluation (side to move): %d", trace.FinalScore))
+	return traceBuilder.String()
+}
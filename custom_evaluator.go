@@ -1,17 +1,30 @@
 package main
 
 import (
+	"math/bits"
+
 	"github.com/A7mad-2000as/GoFish/chessEngine"
 )
 
 const (
-	CheckmateScore             int16 = 10000
-	drawScore                  int16 = 0
-	DrawishPositionScaleFactor int16 = 16
+	CheckmateScore    int16 = 10000
+	drawScore         int16 = 0
+	NormalScaleFactor int16 = 64
+
+	// fiftyMoveRulePlies is the half-move clock at which the game is drawn by the fifty-move rule.
+	fiftyMoveRulePlies int16 = 100
 )
 
+// CustomEvaluator keeps no per-evaluation state, so a single instance can be shared by any number
+// of search threads. The scratch EvaluationData of each call lives on that call's stack, and the
+// pawn hash table and evaluation cache are lock-free.
 type CustomEvaluator struct {
-	evaluationData EvaluationData
+	parameters      *EvaluationParameters
+	baseParameters  EvaluationParameters
+	optionWeights   map[string]int16
+	styleName       string
+	pawnTable       *PawnHashTable
+	evaluationCache *EvaluationCache
 }
 
 type EvaluationData struct {
@@ -19,22 +32,78 @@ type EvaluationData struct {
 	EndgameScores           [2]int16
 	ThreatToEnemyKingPoints [2]uint16
 	EnemyKingAttackerCount  [2]uint8
+	PassedPawns             chessEngine.Bitboard
+	AttackedSquares         [2][6]chessEngine.Bitboard
+	AllAttackedSquares      [2]chessEngine.Bitboard
+	ImmobileRooksAndQueens  chessEngine.Bitboard
+	trace                   *EvaluationTrace
+}
+
+// recordAttacks adds the squares a piece attacks, defended own pieces included, to the attack maps.
+func (evaluationData *EvaluationData) recordAttacks(color uint8, pieceType uint8, attacks chessEngine.Bitboard) {
+	evaluationData.AttackedSquares[color][pieceType] |= attacks
+	evaluationData.AllAttackedSquares[color] |= attacks
+}
+
+func (evaluationData *EvaluationData) addScores(term uint8, color uint8, midgameScore int16, endgameScore int16) {
+	evaluationData.MidgameScores[color] += midgameScore
+	evaluationData.EndgameScores[color] += endgameScore
+	if evaluationData.trace != nil {
+		evaluationData.trace.MidgameScores[term][color] += midgameScore
+		evaluationData.trace.EndgameScores[term][color] += endgameScore
+	}
+}
+
+// NewCustomEvaluator evaluates with a copy of the given weights, which also become the base that
+// play styles are applied on top of. The caller's parameters are never written to.
+func NewCustomEvaluator(parameters *EvaluationParameters) *CustomEvaluator {
+	activeParameters := *parameters
+	return &CustomEvaluator{
+		parameters:      &activeParameters,
+		baseParameters:  *parameters,
+		optionWeights:   make(map[string]int16),
+		styleName:       DefaultStyleName,
+		pawnTable:       NewPawnHashTable(DefaultPawnHashTableSizeMB),
+		evaluationCache: NewEvaluationCache(DefaultEvaluationCacheSizeMB),
+	}
+}
+
+// ResizePawnHashTable replaces the pawn hash table with an empty one of sizeMB megabytes. A size
+// of 0 turns pawn caching off.
+func (evaluator *CustomEvaluator) ResizePawnHashTable(sizeMB int) {
+	evaluator.pawnTable = NewPawnHashTable(sizeMB)
+}
+
+// ResizeEvaluationCache replaces the evaluation cache with an empty one of sizeMB megabytes. A size
+// of 0 turns the cache off.
+func (evaluator *CustomEvaluator) ResizeEvaluationCache(sizeMB int) {
+	evaluator.evaluationCache = NewEvaluationCache(sizeMB)
+}
+
+// ClearCaches drops all cached evaluation results. It must be called whenever a weight changes.
+func (evaluator *CustomEvaluator) ClearCaches() {
+	if evaluator.pawnTable != nil {
+		evaluator.pawnTable.Clear()
+	}
+	if evaluator.evaluationCache != nil {
+		evaluator.evaluationCache.Clear()
+	}
 }
 
 func (evaluator *CustomEvaluator) GetMiddleGamePieceSquareTable() *[6][64]int16 {
-	return &MidGamePieceSquareTables
+	return &evaluator.parameters.MidGamePieceSquareTables
 }
 
 func (evaluator *CustomEvaluator) GetEndGamePieceSquareTable() *[6][64]int16 {
-	return &EndGamePieceSquareTables
+	return &evaluator.parameters.EndGamePieceSquareTables
 }
 
 func (evaluator *CustomEvaluator) GetMiddleGamePieceValues() *[6]int16 {
-	return &MidGamePieceValues
+	return &evaluator.parameters.MidGamePieceValues
 }
 
 func (evaluator *CustomEvaluator) GetEndGamePieceValues() *[6]int16 {
-	return &EndGamePieceValues
+	return &evaluator.parameters.EndGamePieceValues
 }
 
 func (evaluator *CustomEvaluator) GetPhaseValues() *[6]int16 {
@@ -45,76 +114,363 @@ func (evaluator *CustomEvaluator) GetTotalPhaseWeight() int16 {
 	return TotalPhaseIncrement
 }
 
+// EvaluatePosition looks the position up in the evaluation cache before running the full
+// evaluation. Traces always bypass the cache. The cache holds scores before fifty-move damping,
+// which depends on the half-move clock rather than the position hash, so the damping is applied
+// to cached and fresh scores alike.
 func (customClassicEvaluator *CustomEvaluator) EvaluatePosition(position *chessEngine.Position) int16 {
-	if isDrawnState(position) {
+	if int16(position.Rule50) >= fiftyMoveRulePlies {
+		return drawScore
+	}
+	evaluationCache := customClassicEvaluator.evaluationCache
+	if evaluationCache == nil {
+		return customClassicEvaluator.dampForFiftyMoveRule(position, customClassicEvaluator.evaluate(position, nil))
+	}
+	score, foundInCache := evaluationCache.Probe(position.PositionHash)
+	if !foundInCache {
+		score = customClassicEvaluator.evaluate(position, nil)
+		evaluationCache.Store(position.PositionHash, score)
+	}
+	return customClassicEvaluator.dampForFiftyMoveRule(position, score)
+}
+
+// TraceEvaluation evaluates the position like EvaluatePosition while recording every term.
+func (customClassicEvaluator *CustomEvaluator) TraceEvaluation(position *chessEngine.Position) EvaluationTrace {
+	trace := EvaluationTrace{SideToMove: position.SideToMove, FiftyMoveClock: position.Rule50}
+	if int16(position.Rule50) >= fiftyMoveRulePlies {
+		trace.FinalScore = drawScore
+		return trace
+	}
+	trace.UndampedScore = customClassicEvaluator.evaluate(position, &trace)
+	trace.FinalScore = customClassicEvaluator.dampForFiftyMoveRule(position, trace.UndampedScore)
+	return trace
+}
+
+// dampForFiftyMoveRule shrinks the score linearly towards a draw once the half-move clock passes
+// FiftyMoveDampingStart, reaching zero when the fifty-move rule would end the game. A won position
+// without progress then looks worse than one where a pawn move or capture resets the clock.
+func (customClassicEvaluator *CustomEvaluator) dampForFiftyMoveRule(position *chessEngine.Position, score int16) int16 {
+	dampingStart := customClassicEvaluator.parameters.FiftyMoveDampingStart
+	if int16(position.Rule50) <= dampingStart {
+		return score
+	}
+	return int16(int32(score) * int32(fiftyMoveRulePlies-int16(position.Rule50)) / int32(fiftyMoveRulePlies-dampingStart))
+}
+
+func (customClassicEvaluator *CustomEvaluator) evaluate(position *chessEngine.Position, trace *EvaluationTrace) int16 {
+	materialScale := materialScale(position)
+	if trace != nil {
+		trace.MaterialSignature = computeMaterialKey(position).String()
+		trace.MaterialScale = materialScale
+	}
+	if materialScale == 0 {
 		return drawScore
 	}
+	if score, endgameName, isMatingEndgame := evaluateMatingEndgame(position, customClassicEvaluator.parameters); isMatingEndgame {
+		if trace != nil {
+			trace.KnownEndgame = endgameName
+		}
+		return score
+	}
+	if score, isKPK := evaluateKPK(position, customClassicEvaluator.parameters); isKPK {
+		if trace != nil {
+			trace.KnownEndgame = KPKEndgameName
+		}
+		return score
+	}
 	allBitBoard := position.ColorsBitBoard[position.SideToMove] | position.ColorsBitBoard[position.SideToMove^1]
 	var phaseValue = position.Phase
-	customClassicEvaluator.evaluationData = EvaluationData{
+	evaluationData := EvaluationData{
 		MidgameScores: position.MidGameScores,
 		EndgameScores: position.EndGameScores,
+		trace:         trace,
+	}
+	if trace != nil {
+		trace.MidgameScores[MaterialAndPieceSquaresTerm] = position.MidGameScores
+		trace.EndgameScores[MaterialAndPieceSquaresTerm] = position.EndGameScores
+	}
+	customClassicEvaluator.evaluatePawnStructure(position, &evaluationData)
+	customClassicEvaluator.evaluatePassedPawns(position, &evaluationData)
+	customClassicEvaluator.evaluateUnstoppablePassedPawns(position, &evaluationData)
+	for color := chessEngine.Black; color <= chessEngine.White; color++ {
+		pawns := position.PiecesBitBoard[color][chessEngine.Pawn]
+		for pawns != 0 {
+			evaluationData.recordAttacks(color, chessEngine.Pawn, chessEngine.ComputedPawnCaptures[color][pawns.PopMostSignificantBit()])
+		}
+		evaluationData.recordAttacks(color, chessEngine.King, chessEngine.ComputedKingMoves[position.PiecesBitBoard[color][chessEngine.King].MostSignificantBit()])
 	}
 	for allBitBoard != 0 {
 		pieceSquare := allBitBoard.PopMostSignificantBit()
 		pieceType := position.SquareContent[pieceSquare].PieceType
 		pieceColor := position.SquareContent[pieceSquare].Color
 		switch pieceType {
-		case chessEngine.Pawn:
-			customClassicEvaluator.evaluatePawnAtSquare(position, pieceColor, pieceSquare)
 		case chessEngine.Knight:
-			customClassicEvaluator.evaluateKnightAtSquare(position, pieceColor, pieceSquare)
+			customClassicEvaluator.evaluateKnightAtSquare(position, &evaluationData, pieceColor, pieceSquare)
 		case chessEngine.Bishop:
-			customClassicEvaluator.evaluateBishopAtSquare(position, pieceColor, pieceSquare)
+			customClassicEvaluator.evaluateBishopAtSquare(position, &evaluationData, pieceColor, pieceSquare)
 		case chessEngine.Rook:
-			customClassicEvaluator.evaluateRookAtSquare(position, pieceColor, pieceSquare)
+			customClassicEvaluator.evaluateRookAtSquare(position, &evaluationData, pieceColor, pieceSquare)
 		case chessEngine.Queen:
-			customClassicEvaluator.evaluateQueenAtSquare(position, pieceColor, pieceSquare)
+			customClassicEvaluator.evaluateQueenAtSquare(position, &evaluationData, pieceColor, pieceSquare)
 		}
 	}
 	for color := chessEngine.Black; color <= chessEngine.White; color++ {
 		if position.PiecesBitBoard[color][chessEngine.Bishop].CountSetBits() >= 2 {
-			customClassicEvaluator.evaluationData.MidgameScores[color] += MidGameBishopPairBonus
-			customClassicEvaluator.evaluationData.EndgameScores[color] += EndgameBishopPairBonus
+			evaluationData.addScores(BishopPairTerm, color, customClassicEvaluator.parameters.MidGameBishopPairBonus, customClassicEvaluator.parameters.EndgameBishopPairBonus)
 		}
-		customClassicEvaluator.evaluateKingAtSquare(position, color, position.PiecesBitBoard[color][chessEngine.King].MostSignificantBit())
+		customClassicEvaluator.evaluateKingAtSquare(position, &evaluationData, color, position.PiecesBitBoard[color][chessEngine.King].MostSignificantBit())
 	}
-	customClassicEvaluator.evaluationData.MidgameScores[position.SideToMove] += MidGameTempoBonus
+	customClassicEvaluator.evaluateThreats(position, &evaluationData)
+	customClassicEvaluator.evaluateSpace(position, &evaluationData)
+	customClassicEvaluator.evaluateImmobileRooksAndQueens(position, &evaluationData)
+	evaluationData.addScores(TempoTerm, position.SideToMove, customClassicEvaluator.parameters.MidGameTempoBonus, 0)
+
+	currentMidGameScore := evaluationData.MidgameScores[position.SideToMove] - evaluationData.MidgameScores[position.SideToMove^1]
+	currentEndGameScore := evaluationData.EndgameScores[position.SideToMove] - evaluationData.EndgameScores[position.SideToMove^1]
 
-	currentMidGameScore := customClassicEvaluator.evaluationData.MidgameScores[position.SideToMove] - customClassicEvaluator.evaluationData.MidgameScores[position.SideToMove^1]
-	currentEndGameScore := customClassicEvaluator.evaluationData.EndgameScores[position.SideToMove] - customClassicEvaluator.evaluationData.EndgameScores[position.SideToMove^1]
+	endgameScale := customClassicEvaluator.computeEndgameScale(position, evaluationData.PassedPawns, currentEndGameScore)
+	currentEndGameScore = int16(int32(currentEndGameScore) * int32(endgameScale) / int32(NormalScaleFactor))
 
 	scaledPhaseValue := (phaseValue*256 + (TotalPhaseIncrement / 2)) / TotalPhaseIncrement
 	currentScore := int16(((int32(currentMidGameScore) * (int32(256) - int32(scaledPhaseValue))) + (int32(currentEndGameScore) * int32(scaledPhaseValue))) / int32(256))
+	if trace != nil {
+		trace.Phase = phaseValue
+		trace.ScaledPhase = scaledPhaseValue
+		trace.EndgameScale = endgameScale
+		trace.BlendedScore = currentScore
+	}
+
+	return int16(int32(currentScore) * int32(materialScale) / int32(NormalScaleFactor))
+}
+
+// computeEndgameScale returns the factor, out of NormalScaleFactor, that the endgame score is
+// multiplied by. Endings with one bishop each on opposite colours are scaled down, most when no
+// other pieces remain; a larger pawn difference and passed pawns of the stronger side on both wings
+// give some of the winning chances back.
+func (customClassicEvaluator *CustomEvaluator) computeEndgameScale(position *chessEngine.Position, passedPawns chessEngine.Bitboard, endgameScore int16) int16 {
+	parameters := customClassicEvaluator.parameters
+	whiteBishops := position.PiecesBitBoard[chessEngine.White][chessEngine.Bishop]
+	blackBishops := position.PiecesBitBoard[chessEngine.Black][chessEngine.Bishop]
+	if whiteBishops.CountSetBits() != 1 || blackBishops.CountSetBits() != 1 ||
+		isSquareLight(whiteBishops.MostSignificantBit()) == isSquareLight(blackBishops.MostSignificantBit()) {
+		return NormalScaleFactor
+	}
+
+	scale := parameters.OppositeBishopsWithPiecesScale
+	otherPieces := position.ColorsBitBoard[chessEngine.White] | position.ColorsBitBoard[chessEngine.Black]
+	for color := chessEngine.Black; color <= chessEngine.White; color++ {
+		otherPieces &^= position.PiecesBitBoard[color][chessEngine.Pawn] | position.PiecesBitBoard[color][chessEngine.Bishop] | position.PiecesBitBoard[color][chessEngine.King]
+	}
+	if otherPieces == 0 {
+		scale = parameters.OppositeBishopsScale
+	}
+
+	pawnDifference := int16(position.PiecesBitBoard[chessEngine.White][chessEngine.Pawn].CountSetBits() - position.PiecesBitBoard[chessEngine.Black][chessEngine.Pawn].CountSetBits())
+	if pawnDifference < 0 {
+		pawnDifference = -pawnDifference
+	}
+	scale += pawnDifference * parameters.OppositeBishopsScalePerPawnDifference
 
-	if isDrawishState(position) {
-		return currentScore / DrawishPositionScaleFactor
+	strongerSide := chessEngine.White
+	if endgameScore < 0 {
+		strongerSide = chessEngine.Black
+	}
+	strongerSidePassers := passedPawns & position.PiecesBitBoard[strongerSide][chessEngine.Pawn]
+	if strongerSidePassers&QueenSideFilesMask != 0 && strongerSidePassers&KingSideFilesMask != 0 {
+		scale += parameters.OppositeBishopsPassersOnBothWingsScale
 	}
 
-	return currentScore
+	if scale > NormalScaleFactor {
+		return NormalScaleFactor
+	}
+	return scale
 }
-func (customClassicEvaluator *CustomEvaluator) evaluatePawnAtSquare(position *chessEngine.Position, color uint8, square uint8) {
+
+// evaluatePawnStructure adds the pawn-only terms of both sides, taken from the pawn hash table when
+// the same pawn structure has been evaluated before.
+func (customClassicEvaluator *CustomEvaluator) evaluatePawnStructure(position *chessEngine.Position, evaluationData *EvaluationData) {
+	whitePawns := position.PiecesBitBoard[chessEngine.White][chessEngine.Pawn]
+	blackPawns := position.PiecesBitBoard[chessEngine.Black][chessEngine.Pawn]
+
+	var pawnStructure PawnStructureEntry
+	foundInTable := false
+	if customClassicEvaluator.pawnTable != nil {
+		pawnStructure, foundInTable = customClassicEvaluator.pawnTable.Probe(whitePawns, blackPawns)
+	}
+	if !foundInTable {
+		for color := chessEngine.Black; color <= chessEngine.White; color++ {
+			pawns := position.PiecesBitBoard[color][chessEngine.Pawn]
+			for pawns != 0 {
+				customClassicEvaluator.evaluatePawnAtSquare(position, &pawnStructure, color, pawns.PopMostSignificantBit())
+			}
+		}
+		if customClassicEvaluator.pawnTable != nil {
+			customClassicEvaluator.pawnTable.Store(whitePawns, blackPawns, &pawnStructure)
+		}
+	}
+
+	for color := chessEngine.Black; color <= chessEngine.White; color++ {
+		evaluationData.addScores(PawnStructureTerm, color, pawnStructure.MidgameScores[color], pawnStructure.EndgameScores[color])
+	}
+	evaluationData.PassedPawns = pawnStructure.PassedPawns
+}
+
+// evaluatePassedPawns adds the endgame terms of passed pawns that depend on more than the pawns:
+// king distances to the stop square, a blockaded or attacked stop square, a free path to
+// promotion and a rook or queen behind the pawn. The terms grow with the pawn's rank from its
+// fourth rank on.
+func (customClassicEvaluator *CustomEvaluator) evaluatePassedPawns(position *chessEngine.Position, evaluationData *EvaluationData) {
+	parameters := customClassicEvaluator.parameters
+	allBitBoard := position.ColorsBitBoard[chessEngine.White] | position.ColorsBitBoard[chessEngine.Black]
+	passedPawns := evaluationData.PassedPawns
+	for passedPawns != 0 {
+		square := passedPawns.PopMostSignificantBit()
+		color := position.SquareContent[square].Color
+		relativeRank := int16(chessEngine.Rank(square))
+		if color == chessEngine.Black {
+			relativeRank = 7 - relativeRank
+		}
+		if relativeRank < 3 {
+			continue
+		}
+		rankWeight := relativeRank - 2
+		stopSquare := PawnStopSquares[color][square]
+
+		ownKingSquare := position.PiecesBitBoard[color][chessEngine.King].MostSignificantBit()
+		enemyKingSquare := position.PiecesBitBoard[color^1][chessEngine.King].MostSignificantBit()
+		endgameScore := (int16(SquareDistances[enemyKingSquare][stopSquare])*parameters.EndGamePassedPawnEnemyKingDistanceBonus -
+			int16(SquareDistances[ownKingSquare][stopSquare])*parameters.EndGamePassedPawnOwnKingDistancePenalty) * rankWeight
+
+		if position.ColorsBitBoard[color^1]&chessEngine.BitboardForSquare[stopSquare] != 0 {
+			endgameScore -= parameters.EndGamePassedPawnBlockadePenalty * rankWeight
+		} else if isSquareAttackedByColor(position, stopSquare, color^1, allBitBoard) {
+			endgameScore -= parameters.EndGamePassedPawnAttackedStopSquarePenalty * rankWeight
+		}
+
+		promotionPath := CheckDoublePawnOnSquareMask[color][square]
+		if promotionPath&allBitBoard == 0 {
+			isPathFree := true
+			for pathSquares := promotionPath; pathSquares != 0 && isPathFree; {
+				isPathFree = !isSquareAttackedByColor(position, pathSquares.PopMostSignificantBit(), color^1, allBitBoard)
+			}
+			if isPathFree {
+				endgameScore += parameters.EndGamePassedPawnFreePathBonus * rankWeight
+			}
+		}
+
+		majorPiecesBehind := chessEngine.GetRookPseudoLegalMoves(square, allBitBoard) & CheckDoublePawnOnSquareMask[color^1][square] &
+			(position.PiecesBitBoard[color][chessEngine.Rook] | position.PiecesBitBoard[color][chessEngine.Queen])
+		if majorPiecesBehind != 0 {
+			endgameScore += parameters.EndGamePassedPawnRookOrQueenBehindBonus * rankWeight
+		}
+
+		evaluationData.addScores(PassedPawnTerm, color, 0, endgameScore)
+	}
+}
+
+// evaluateUnstoppablePassedPawns finds the passed pawns that the enemy king cannot catch when the
+// enemy has only its king and pawns left, using the rule of the square with the side to move. A
+// side with such a runner gets UnstoppablePassedPawnBonus. When both sides have one, only the side
+// whose pawn promotes first gets the bonus.
+func (customClassicEvaluator *CustomEvaluator) evaluateUnstoppablePassedPawns(position *chessEngine.Position, evaluationData *EvaluationData) {
+	const noRunner = 255
+	promotionPlies := [2]uint8{noRunner, noRunner}
+
+	passedPawns := evaluationData.PassedPawns
+	for passedPawns != 0 {
+		square := passedPawns.PopMostSignificantBit()
+		color := position.SquareContent[square].Color
+		enemyPieces := position.ColorsBitBoard[color^1] &^ (position.PiecesBitBoard[color^1][chessEngine.Pawn] | position.PiecesBitBoard[color^1][chessEngine.King])
+		if enemyPieces != 0 || CheckDoublePawnOnSquareMask[color][square]&position.ColorsBitBoard[color] != 0 {
+			continue
+		}
+
+		promotionSquare := chessEngine.File(square) + 56
+		pawnDistance := 7 - chessEngine.Rank(square)
+		if color == chessEngine.Black {
+			promotionSquare = chessEngine.File(square)
+			pawnDistance = chessEngine.Rank(square)
+		}
+		if pawnDistance == 6 {
+			// The double push from the starting rank.
+			pawnDistance = 5
+		}
+
+		enemyKingSquare := position.PiecesBitBoard[color^1][chessEngine.King].MostSignificantBit()
+		enemyKingDistance := SquareDistances[enemyKingSquare][promotionSquare]
+		if position.SideToMove == color^1 && enemyKingDistance > 0 {
+			enemyKingDistance--
+		}
+		if pawnDistance >= enemyKingDistance {
+			continue
+		}
+
+		// Plies until promotion, counted from the side to move, so the two runners can be compared.
+		plies := 2*pawnDistance - 1
+		if position.SideToMove != color {
+			plies++
+		}
+		if plies < promotionPlies[color] {
+			promotionPlies[color] = plies
+		}
+	}
+
+	for color := chessEngine.Black; color <= chessEngine.White; color++ {
+		if promotionPlies[color] != noRunner && promotionPlies[color] < promotionPlies[color^1] {
+			bonus := customClassicEvaluator.parameters.UnstoppablePassedPawnBonus
+			evaluationData.addScores(PassedPawnTerm, color, bonus, bonus)
+		}
+	}
+}
+
+func (customClassicEvaluator *CustomEvaluator) evaluatePawnAtSquare(position *chessEngine.Position, pawnStructure *PawnStructureEntry, color uint8, square uint8) {
 	enemyPawns := position.PiecesBitBoard[color^1][chessEngine.Pawn]
 	sideToMovePawn := position.PiecesBitBoard[color][chessEngine.Pawn]
 	fileOfSq := chessEngine.File(square)
 	isIsolated := CheckForIsolatedPawnOnFileMasks[fileOfSq]&sideToMovePawn == 0
 	isDoubled := CheckDoublePawnOnSquareMask[color][square]&sideToMovePawn != 0
 	isPassedAndNotBlockedByFriendlyPawn := CheckPassedPawnOnSquareMask[color][square]&enemyPawns == 0 && sideToMovePawn&CheckDoublePawnOnSquareMask[color][square] == 0
+	isSupported := chessEngine.ComputedPawnCaptures[color^1][square]&sideToMovePawn != 0
+	isInPhalanx := CheckForIsolatedPawnOnFileMasks[fileOfSq]&chessEngine.SetRankMasks[chessEngine.Rank(square)]&sideToMovePawn != 0
+
+	// A backward pawn has no friendly pawn beside or behind it on the adjacent files, and cannot
+	// advance because an enemy pawn attacks its stop square. Isolated pawns are already penalised.
+	isBackward := false
+	if stopSquare := PawnStopSquares[color][square]; !isIsolated && stopSquare != chessEngine.NoneSquare {
+		isBackward = CheckBackwardPawnOnSquareMask[color][square]&sideToMovePawn == 0 && chessEngine.ComputedPawnCaptures[color][stopSquare]&enemyPawns != 0
+	}
 
 	if isIsolated {
-		customClassicEvaluator.evaluationData.MidgameScores[color] -= MidGameIsolatedPawnPenalty
-		customClassicEvaluator.evaluationData.EndgameScores[color] -= EndGameIsolatedPawnPenalty
+		pawnStructure.MidgameScores[color] -= customClassicEvaluator.parameters.MidGameIsolatedPawnPenalty
+		pawnStructure.EndgameScores[color] -= customClassicEvaluator.parameters.EndGameIsolatedPawnPenalty
 	}
 	if isDoubled {
-		customClassicEvaluator.evaluationData.MidgameScores[color] -= MidGameDoubledPawnPenalty
-		customClassicEvaluator.evaluationData.EndgameScores[color] -= EndGameDoubledPawnPenalty
+		pawnStructure.MidgameScores[color] -= customClassicEvaluator.parameters.MidGameDoubledPawnPenalty
+		pawnStructure.EndgameScores[color] -= customClassicEvaluator.parameters.EndGameDoubledPawnPenalty
+	}
+	if isSupported || isInPhalanx {
+		relativeRank := chessEngine.Rank(square)
+		if color == chessEngine.Black {
+			relativeRank = 7 - relativeRank
+		}
+		pawnStructure.MidgameScores[color] += customClassicEvaluator.parameters.MidGameConnectedPawnBonusPerRank[relativeRank]
+		pawnStructure.EndgameScores[color] += customClassicEvaluator.parameters.EndGameConnectedPawnBonusPerRank[relativeRank]
+	}
+	if isBackward {
+		pawnStructure.MidgameScores[color] -= customClassicEvaluator.parameters.MidGameBackwardPawnPenalty
+		pawnStructure.EndgameScores[color] -= customClassicEvaluator.parameters.EndGameBackwardPawnPenalty
+		if CheckDoublePawnOnSquareMask[color][square]&enemyPawns == 0 {
+			pawnStructure.MidgameScores[color] -= customClassicEvaluator.parameters.MidGameBackwardPawnOnHalfOpenFilePenalty
+			pawnStructure.EndgameScores[color] -= customClassicEvaluator.parameters.EndGameBackwardPawnOnHalfOpenFilePenalty
+		}
 	}
 	if isPassedAndNotBlockedByFriendlyPawn {
-		customClassicEvaluator.evaluationData.MidgameScores[color] += MidGamePassedPawnSquareTables[chessEngine.BoardSquaresNormalAndFlipped[color][square]]
-		customClassicEvaluator.evaluationData.EndgameScores[color] += EndGamePassedPawnSquareTables[chessEngine.BoardSquaresNormalAndFlipped[color][square]]
+		pawnStructure.MidgameScores[color] += customClassicEvaluator.parameters.MidGamePassedPawnSquareTables[chessEngine.BoardSquaresNormalAndFlipped[color][square]]
+		pawnStructure.EndgameScores[color] += customClassicEvaluator.parameters.EndGamePassedPawnSquareTables[chessEngine.BoardSquaresNormalAndFlipped[color][square]]
+		pawnStructure.PassedPawns.SetBit(square)
 	}
 }
-func (customClassicEvaluator *CustomEvaluator) evaluateKnightAtSquare(position *chessEngine.Position, color uint8, square uint8) {
+func (customClassicEvaluator *CustomEvaluator) evaluateKnightAtSquare(position *chessEngine.Position, evaluationData *EvaluationData, color uint8, square uint8) {
 	var enemyPawns chessEngine.Bitboard = position.PiecesBitBoard[color^1][chessEngine.Pawn]
 	var sideToMovePawns chessEngine.Bitboard = position.PiecesBitBoard[color][chessEngine.Pawn]
 
@@ -123,21 +479,25 @@ func (customClassicEvaluator *CustomEvaluator) evaluateKnightAtSquare(position *
 	isTheKnightProtectedByFriendlyPawn := chessEngine.ComputedPawnCaptures[color^1][square]&sideToMovePawns != 0
 	if noEnemyCanAttackKnight && isTheKnightProtectedByFriendlyPawn &&
 		chessEngine.BoardRanksNormalAndFlipped[color][chessEngine.Rank(square)] >= chessEngine.Rank5 {
-		customClassicEvaluator.evaluationData.MidgameScores[color] += MidGameKnightOnOutpostBonus
-		customClassicEvaluator.evaluationData.EndgameScores[color] += EndGameKnightOnOutpostBonus
+		evaluationData.addScores(OutpostTerm, color, customClassicEvaluator.parameters.MidGameKnightOnOutpostBonus, customClassicEvaluator.parameters.EndGameKnightOnOutpostBonus)
 	}
 	// mobility evaluation
 	var sideToMoveBitBoard chessEngine.Bitboard = position.ColorsBitBoard[color]
+	evaluationData.recordAttacks(color, chessEngine.Knight, chessEngine.ComputedKnightMoves[square])
 	var knightMoves chessEngine.Bitboard = chessEngine.ComputedKnightMoves[square] & ^sideToMoveBitBoard
 	var knightSafeMoves chessEngine.Bitboard = filterMoveAndKeepTheSafeMoves(knightMoves, color, enemyPawns)
 	mobility := int16(knightSafeMoves.CountSetBits())
-	customClassicEvaluator.evaluationData.MidgameScores[color] += (mobility - 4) * MidGameMobilityScoresPerPiece[chessEngine.Knight]
-	customClassicEvaluator.evaluationData.EndgameScores[color] += (mobility - 4) * EndGameMobilityScoresPerPiece[chessEngine.Knight]
+	evaluationData.addScores(MobilityTerm, color, (mobility-4)*customClassicEvaluator.parameters.MidGameMobilityScoresPerPiece[chessEngine.Knight], (mobility-4)*customClassicEvaluator.parameters.EndGameMobilityScoresPerPiece[chessEngine.Knight])
+
+	relativeKnightSquare := relativeSquare(color, square)
+	if knightSafeMoves == 0 && (relativeKnightSquare == 56 || relativeKnightSquare == 63) {
+		evaluationData.addScores(TrappedPiecesTerm, color, -customClassicEvaluator.parameters.MidGameTrappedKnightInCornerPenalty, -customClassicEvaluator.parameters.EndGameTrappedKnightInCornerPenalty)
+	}
 
 	// attacks on enemy king evaluation
-	customClassicEvaluator.evaluateAttacksOnEnemyKing(position, knightSafeMoves, color, chessEngine.Knight)
+	customClassicEvaluator.evaluateAttacksOnEnemyKing(position, evaluationData, knightSafeMoves, color, chessEngine.Knight)
 }
-func (customClassicEvaluator *CustomEvaluator) evaluateBishopAtSquare(position *chessEngine.Position, color uint8, square uint8) {
+func (customClassicEvaluator *CustomEvaluator) evaluateBishopAtSquare(position *chessEngine.Position, evaluationData *EvaluationData, color uint8, square uint8) {
 	enemyPawns := position.PiecesBitBoard[color^1][chessEngine.Pawn]
 	sideToMovePawns := position.PiecesBitBoard[color][chessEngine.Pawn]
 	sideToMoveBitBoard := position.ColorsBitBoard[color]
@@ -147,88 +507,285 @@ func (customClassicEvaluator *CustomEvaluator) evaluateBishopAtSquare(position *
 	isTheBishopProtectedByFriendlyPawn := chessEngine.ComputedPawnCaptures[color^1][square]&sideToMovePawns != 0
 	if noEnemyCanAttackBishop && isTheBishopProtectedByFriendlyPawn &&
 		chessEngine.BoardRanksNormalAndFlipped[color][chessEngine.Rank(square)] >= chessEngine.Rank5 {
-		customClassicEvaluator.evaluationData.MidgameScores[color] += MidGameBishopOnOutpostBonus
-		customClassicEvaluator.evaluationData.EndgameScores[color] += EndGameBishopOnOutpostBonus
+		evaluationData.addScores(OutpostTerm, color, customClassicEvaluator.parameters.MidGameBishopOnOutpostBonus, customClassicEvaluator.parameters.EndGameBishopOnOutpostBonus)
 	}
 
+	// A bishop on a7 or h7 is shut in by an enemy pawn on b6 or g6.
+	relativeBishopSquare := relativeSquare(color, square)
+	if (relativeBishopSquare == 48 && enemyPawns&chessEngine.BitboardForSquare[relativeSquare(color, 41)] != 0) ||
+		(relativeBishopSquare == 55 && enemyPawns&chessEngine.BitboardForSquare[relativeSquare(color, 46)] != 0) {
+		evaluationData.addScores(TrappedPiecesTerm, color, -customClassicEvaluator.parameters.MidGameTrappedBishopPenalty, -customClassicEvaluator.parameters.EndGameTrappedBishopPenalty)
+	}
+
+	// bad bishop evaluation: own pawns on the bishop's square colour, blocked central ones counting extra
+	bishopSquareColorMask := DarkSquaresMask
+	if isSquareLight(square) {
+		bishopSquareColorMask = LightSquaresMask
+	}
+	pawnsOnBishopColor := sideToMovePawns & bishopSquareColorMask
+	blockedCentralPawnCount := int16(0)
+	for centralPawns := pawnsOnBishopColor & CentralFilesMask; centralPawns != 0; {
+		stopSquare := PawnStopSquares[color][centralPawns.PopMostSignificantBit()]
+		if stopSquare != chessEngine.NoneSquare && allBitBoard&chessEngine.BitboardForSquare[stopSquare] != 0 {
+			blockedCentralPawnCount++
+		}
+	}
+	pawnCount := int16(pawnsOnBishopColor.CountSetBits())
+	evaluationData.addScores(BadBishopTerm, color,
+		-pawnCount*customClassicEvaluator.parameters.MidGameBadBishopPawnPenalty-blockedCentralPawnCount*customClassicEvaluator.parameters.MidGameBadBishopBlockedCentralPawnPenalty,
+		-pawnCount*customClassicEvaluator.parameters.EndGameBadBishopPawnPenalty-blockedCentralPawnCount*customClassicEvaluator.parameters.EndGameBadBishopBlockedCentralPawnPenalty)
+
 	//mobility evaluation
-	var bishopMoves chessEngine.Bitboard = chessEngine.GetBishopPseudoLegalMoves(square, allBitBoard) & ^sideToMoveBitBoard
+	bishopAttacks := chessEngine.GetBishopPseudoLegalMoves(square, allBitBoard)
+	evaluationData.recordAttacks(color, chessEngine.Bishop, bishopAttacks)
+	var bishopMoves chessEngine.Bitboard = bishopAttacks & ^sideToMoveBitBoard
 	mobility := int16(bishopMoves.CountSetBits())
-	customClassicEvaluator.evaluationData.MidgameScores[color] += (mobility - 7) * MidGameMobilityScoresPerPiece[chessEngine.Bishop]
-	customClassicEvaluator.evaluationData.EndgameScores[color] += (mobility - 7) * EndGameMobilityScoresPerPiece[chessEngine.Bishop]
+	evaluationData.addScores(MobilityTerm, color, (mobility-7)*customClassicEvaluator.parameters.MidGameMobilityScoresPerPiece[chessEngine.Bishop], (mobility-7)*customClassicEvaluator.parameters.EndGameMobilityScoresPerPiece[chessEngine.Bishop])
 
 	// attacks on enemy king evaluation
-	customClassicEvaluator.evaluateAttacksOnEnemyKing(position, bishopMoves, color, chessEngine.Bishop)
+	customClassicEvaluator.evaluateAttacksOnEnemyKing(position, evaluationData, bishopMoves, color, chessEngine.Bishop)
 
 }
-func (customClassicEvaluator *CustomEvaluator) evaluateRookAtSquare(position *chessEngine.Position, color uint8, square uint8) {
+func (customClassicEvaluator *CustomEvaluator) evaluateRookAtSquare(position *chessEngine.Position, evaluationData *EvaluationData, color uint8, square uint8) {
 	enemyKingSquare := position.PiecesBitBoard[color^1][chessEngine.King].MostSignificantBit()
 	allPawns := position.PiecesBitBoard[color][chessEngine.Pawn] | position.PiecesBitBoard[color^1][chessEngine.Pawn]
 	sideToMoveBitBoard := position.ColorsBitBoard[color]
 	allBitBoard := position.ColorsBitBoard[color] | position.ColorsBitBoard[color^1]
 
 	if chessEngine.BoardRanksNormalAndFlipped[color][chessEngine.Rank(square)] == chessEngine.Rank7 && chessEngine.BoardRanksNormalAndFlipped[color][chessEngine.Rank(enemyKingSquare)] >= chessEngine.Rank7 {
-		customClassicEvaluator.evaluationData.EndgameScores[color] += EndGameBonusForRookOrQueenOnSeventhRank
+		evaluationData.addScores(RookAndQueenPlacementTerm, color, 0, customClassicEvaluator.parameters.EndGameBonusForRookOrQueenOnSeventhRank)
 	}
 
-	if chessEngine.SetFileMasks[chessEngine.File(square)]&allPawns == 0 {
-		customClassicEvaluator.evaluationData.MidgameScores[color] += MidGameRookOnOpenFileBonus
+	rookFile := chessEngine.SetFileMasks[chessEngine.File(square)]
+	if rookFile&allPawns == 0 {
+		evaluationData.addScores(RookAndQueenPlacementTerm, color, customClassicEvaluator.parameters.MidGameRookOnOpenFileBonus, 0)
+	} else if rookFile&position.PiecesBitBoard[color][chessEngine.Pawn] == 0 {
+		evaluationData.addScores(RookAndQueenPlacementTerm, color, customClassicEvaluator.parameters.MidGameRookOnSemiOpenFileBonus, customClassicEvaluator.parameters.EndGameRookOnSemiOpenFileBonus)
 	}
 
-	rookMoves := chessEngine.GetRookPseudoLegalMoves(square, allBitBoard) & ^sideToMoveBitBoard
+	// Tarrasch rule: rooks belong behind passed pawns, the own ones and the enemy's alike, and not in
+	// front of their own.
+	rookAttacks := chessEngine.GetRookPseudoLegalMoves(square, allBitBoard)
+	enemyPawnAttacks := evaluationData.AttackedSquares[color^1][chessEngine.Pawn]
+	evaluationData.recordAttacks(color, chessEngine.Rook, rookAttacks)
+	visiblePassedPawns := rookAttacks & rookFile & evaluationData.PassedPawns
+	for visiblePassedPawns != 0 {
+		pawnSquare := visiblePassedPawns.PopMostSignificantBit()
+		pawnColor := position.SquareContent[pawnSquare].Color
+		if CheckDoublePawnOnSquareMask[pawnColor^1][pawnSquare]&chessEngine.BitboardForSquare[square] != 0 {
+			evaluationData.addScores(RookAndQueenPlacementTerm, color, customClassicEvaluator.parameters.MidGameRookBehindPassedPawnBonus, customClassicEvaluator.parameters.EndGameRookBehindPassedPawnBonus)
+		} else if pawnColor == color {
+			evaluationData.addScores(RookAndQueenPlacementTerm, color, -customClassicEvaluator.parameters.MidGameRookInFrontOfOwnPassedPawnPenalty, -customClassicEvaluator.parameters.EndGameRookInFrontOfOwnPassedPawnPenalty)
+		}
+	}
+
+	rookMoves := rookAttacks & ^sideToMoveBitBoard
 	mobility := int16(rookMoves.CountSetBits())
-	customClassicEvaluator.evaluationData.MidgameScores[color] += (mobility - 7) * MidGameMobilityScoresPerPiece[chessEngine.Rook]
-	customClassicEvaluator.evaluationData.EndgameScores[color] += (mobility - 7) * EndGameMobilityScoresPerPiece[chessEngine.Rook]
-	customClassicEvaluator.evaluateAttacksOnEnemyKing(position, rookMoves, color, chessEngine.Rook)
+	if rookMoves&^enemyPawnAttacks == 0 {
+		evaluationData.ImmobileRooksAndQueens.SetBit(square)
+	}
+	if mobility <= 3 && isRookShutInByKing(position, color, square) {
+		evaluationData.addScores(TrappedPiecesTerm, color, -customClassicEvaluator.parameters.MidGameRookShutInByKingPenalty, -customClassicEvaluator.parameters.EndGameRookShutInByKingPenalty)
+	}
+	evaluationData.addScores(MobilityTerm, color, (mobility-7)*customClassicEvaluator.parameters.MidGameMobilityScoresPerPiece[chessEngine.Rook], (mobility-7)*customClassicEvaluator.parameters.EndGameMobilityScoresPerPiece[chessEngine.Rook])
+	customClassicEvaluator.evaluateAttacksOnEnemyKing(position, evaluationData, rookMoves, color, chessEngine.Rook)
 
 }
-func (customClassicEvaluator *CustomEvaluator) evaluateQueenAtSquare(position *chessEngine.Position, color uint8, square uint8) {
+func (customClassicEvaluator *CustomEvaluator) evaluateQueenAtSquare(position *chessEngine.Position, evaluationData *EvaluationData, color uint8, square uint8) {
 	enemyKingSquare := position.PiecesBitBoard[color^1][chessEngine.King].MostSignificantBit()
 	sideToMoveBitBoard := position.ColorsBitBoard[color]
 	allBitBoard := position.ColorsBitBoard[color] | position.ColorsBitBoard[color^1]
 
 	if chessEngine.BoardRanksNormalAndFlipped[color][chessEngine.Rank(square)] == chessEngine.Rank7 && chessEngine.BoardRanksNormalAndFlipped[color][chessEngine.Rank(enemyKingSquare)] >= chessEngine.Rank7 {
-		customClassicEvaluator.evaluationData.EndgameScores[color] += EndGameBonusForRookOrQueenOnSeventhRank
+		evaluationData.addScores(RookAndQueenPlacementTerm, color, 0, customClassicEvaluator.parameters.EndGameBonusForRookOrQueenOnSeventhRank)
+	}
+	queenAttacks := chessEngine.GetBishopPseudoLegalMoves(square, allBitBoard) | chessEngine.GetRookPseudoLegalMoves(square, allBitBoard)
+	evaluationData.recordAttacks(color, chessEngine.Queen, queenAttacks)
+	queenMoves := queenAttacks & ^sideToMoveBitBoard
+	if queenMoves&^evaluationData.AttackedSquares[color^1][chessEngine.Pawn] == 0 {
+		evaluationData.ImmobileRooksAndQueens.SetBit(square)
 	}
-	queenMoves := (chessEngine.GetBishopPseudoLegalMoves(square, allBitBoard) | chessEngine.GetRookPseudoLegalMoves(square, allBitBoard)) & ^sideToMoveBitBoard
 	mobility := int16(queenMoves.CountSetBits())
 
-	customClassicEvaluator.evaluationData.MidgameScores[color] += (mobility - 14) * MidGameMobilityScoresPerPiece[chessEngine.Queen]
-	customClassicEvaluator.evaluationData.EndgameScores[color] += (mobility - 14) * EndGameMobilityScoresPerPiece[chessEngine.Queen]
+	evaluationData.addScores(MobilityTerm, color, (mobility-14)*customClassicEvaluator.parameters.MidGameMobilityScoresPerPiece[chessEngine.Queen], (mobility-14)*customClassicEvaluator.parameters.EndGameMobilityScoresPerPiece[chessEngine.Queen])
+
+	customClassicEvaluator.evaluateAttacksOnEnemyKing(position, evaluationData, queenMoves, color, chessEngine.Queen)
+}
+
+// evaluateThreats scores attacks on non-king pieces from the attack maps recorded before and while
+// evaluating the pieces: pieces attacked by pawns, rooks and queens attacked by minor pieces, queens attacked
+// by rooks, hanging pieces, and safe pawn pushes that would attack a piece. It must run after every
+// piece has been evaluated.
+func (customClassicEvaluator *CustomEvaluator) evaluateThreats(position *chessEngine.Position, evaluationData *EvaluationData) {
+	parameters := customClassicEvaluator.parameters
+	allBitBoard := position.ColorsBitBoard[chessEngine.White] | position.ColorsBitBoard[chessEngine.Black]
+	for color := chessEngine.Black; color <= chessEngine.White; color++ {
+		enemyPieces := &position.PiecesBitBoard[color^1]
+		ownAttacks := &evaluationData.AttackedSquares[color]
+		enemyNonPawnPieces := enemyPieces[chessEngine.Knight] | enemyPieces[chessEngine.Bishop] | enemyPieces[chessEngine.Rook] | enemyPieces[chessEngine.Queen]
+		enemyMajorPieces := enemyPieces[chessEngine.Rook] | enemyPieces[chessEngine.Queen]
+
+		threatCount := int16((ownAttacks[chessEngine.Pawn] & enemyNonPawnPieces).CountSetBits())
+		evaluationData.addScores(ThreatTerm, color, threatCount*parameters.MidGameThreatByPawnBonus, threatCount*parameters.EndGameThreatByPawnBonus)
+
+		threatCount = int16(((ownAttacks[chessEngine.Knight] | ownAttacks[chessEngine.Bishop]) & enemyMajorPieces).CountSetBits())
+		evaluationData.addScores(ThreatTerm, color, threatCount*parameters.MidGameThreatByMinorOnMajorBonus, threatCount*parameters.EndGameThreatByMinorOnMajorBonus)
+
+		threatCount = int16((ownAttacks[chessEngine.Rook] & enemyPieces[chessEngine.Queen]).CountSetBits())
+		evaluationData.addScores(ThreatTerm, color, threatCount*parameters.MidGameThreatByRookOnQueenBonus, threatCount*parameters.EndGameThreatByRookOnQueenBonus)
+
+		hangingPieces := enemyNonPawnPieces & evaluationData.AllAttackedSquares[color] &^ evaluationData.AllAttackedSquares[color^1]
+		threatCount = int16(hangingPieces.CountSetBits())
+		evaluationData.addScores(ThreatTerm, color, threatCount*parameters.MidGameHangingPieceBonus, threatCount*parameters.EndGameHangingPieceBonus)
+
+		// A push is safe when its square is not attacked by an enemy pawn, and is either defended
+		// or not attacked at all.
+		var safePushSquares chessEngine.Bitboard
+		pawns := position.PiecesBitBoard[color][chessEngine.Pawn]
+		for pawns != 0 {
+			pawnSquare := pawns.PopMostSignificantBit()
+			pushSquare := PawnStopSquares[color][pawnSquare]
+			if pushSquare == chessEngine.NoneSquare || allBitBoard&chessEngine.BitboardForSquare[pushSquare] != 0 {
+				continue
+			}
+			safePushSquares |= chessEngine.BitboardForSquare[pushSquare]
+			if chessEngine.BoardRanksNormalAndFlipped[color][chessEngine.Rank(pawnSquare)] == chessEngine.Rank2 {
+				doublePushSquare := PawnStopSquares[color][pushSquare]
+				if allBitBoard&chessEngine.BitboardForSquare[doublePushSquare] == 0 {
+					safePushSquares |= chessEngine.BitboardForSquare[doublePushSquare]
+				}
+			}
+		}
+		safePushSquares &^= evaluationData.AttackedSquares[color^1][chessEngine.Pawn]
+		safePushSquares &= evaluationData.AllAttackedSquares[color] | ^evaluationData.AllAttackedSquares[color^1]
+
+		var pushThreats chessEngine.Bitboard
+		for safePushSquares != 0 {
+			pushThreats |= chessEngine.ComputedPawnCaptures[color][safePushSquares.PopMostSignificantBit()]
+		}
+		threatCount = int16((pushThreats & enemyNonPawnPieces).CountSetBits())
+		evaluationData.addScores(ThreatTerm, color, threatCount*parameters.MidGameSafePawnPushThreatBonus, threatCount*parameters.EndGameSafePawnPushThreatBonus)
+	}
+}
+
+// evaluateSpace counts the central squares on each side's second to fourth ranks that are neither
+// occupied by an own pawn nor attacked by an enemy pawn, with the squares behind own pawns counted
+// twice. The count is weighted by the number of own pieces, and the term is skipped once the phase
+// passes SpaceMaximumPhase. It reads the pawn attack maps that evaluate records before the piece
+// pass.
+func (customClassicEvaluator *CustomEvaluator) evaluateSpace(position *chessEngine.Position, evaluationData *EvaluationData) {
+	if position.Phase > customClassicEvaluator.parameters.SpaceMaximumPhase {
+		return
+	}
+	for color := chessEngine.Black; color <= chessEngine.White; color++ {
+		ownPawns := position.PiecesBitBoard[color][chessEngine.Pawn]
+		safeSquares := SpaceMasks[color] &^ ownPawns &^ evaluationData.AttackedSquares[color^1][chessEngine.Pawn]
+
+		var squaresBehindPawns chessEngine.Bitboard
+		for pawns := ownPawns; pawns != 0; {
+			squaresBehindPawns |= CheckDoublePawnOnSquareMask[color^1][pawns.PopMostSignificantBit()]
+		}
+
+		spaceCount := int32(safeSquares.CountSetBits() + (safeSquares & squaresBehindPawns).CountSetBits())
+		pieceCount := int32(position.ColorsBitBoard[color].CountSetBits())
+		evaluationData.addScores(SpaceTerm, color, int16(spaceCount*pieceCount*int32(customClassicEvaluator.parameters.MidGameSpaceWeight)/64), 0)
+	}
+}
 
-	customClassicEvaluator.evaluateAttacksOnEnemyKing(position, queenMoves, color, chessEngine.Queen)
+// evaluateImmobileRooksAndQueens penalises the rooks and queens that have no safe move and are
+// attacked, as they are likely to be lost. A piece that merely has not moved yet, such as a rook in
+// its corner in the opening, is not attacked and is left alone.
+func (customClassicEvaluator *CustomEvaluator) evaluateImmobileRooksAndQueens(position *chessEngine.Position, evaluationData *EvaluationData) {
+	immobilePieces := evaluationData.ImmobileRooksAndQueens
+	for immobilePieces != 0 {
+		square := immobilePieces.PopMostSignificantBit()
+		color := position.SquareContent[square].Color
+		if evaluationData.AllAttackedSquares[color^1]&chessEngine.BitboardForSquare[square] != 0 {
+			evaluationData.addScores(TrappedPiecesTerm, color, -customClassicEvaluator.parameters.MidGameImmobileRookOrQueenPenalty, -customClassicEvaluator.parameters.EndGameImmobileRookOrQueenPenalty)
+		}
+	}
 }
 
-func (customClassicEvaluator *CustomEvaluator) evaluateKingAtSquare(position *chessEngine.Position, color uint8, square uint8) {
-	threatPointOnSideToMoveKing := customClassicEvaluator.evaluationData.ThreatToEnemyKingPoints[color^1]
+func (customClassicEvaluator *CustomEvaluator) evaluateKingAtSquare(position *chessEngine.Position, evaluationData *EvaluationData, color uint8, square uint8) {
+	threatPointOnSideToMoveKing := evaluationData.ThreatToEnemyKingPoints[color^1]
 	kingFile := chessEngine.SetFileMasks[chessEngine.File(square)]
 	kingLeftFile, kingRightFile := ((kingFile & chessEngine.ClearFileMasks[chessEngine.FileA]) << 1), ((kingFile & chessEngine.ClearFileMasks[chessEngine.FileH]) >> 1)
 	sideToMovePawns := position.PiecesBitBoard[color][chessEngine.Pawn]
 
 	var semipOpenFilePenality uint16 = 0
 	if kingFile&sideToMovePawns == 0 {
-		semipOpenFilePenality += uint16(SemiOpenFileBesideKingPenalty)
+		semipOpenFilePenality += uint16(customClassicEvaluator.parameters.SemiOpenFileBesideKingPenalty)
 	}
 	if kingLeftFile != 0 && kingLeftFile&sideToMovePawns == 0 {
-		semipOpenFilePenality += uint16(SemiOpenFileBesideKingPenalty)
+		semipOpenFilePenality += uint16(customClassicEvaluator.parameters.SemiOpenFileBesideKingPenalty)
 	}
 	if kingRightFile != 0 && kingRightFile&sideToMovePawns == 0 {
-		semipOpenFilePenality += uint16(SemiOpenFileBesideKingPenalty)
+		semipOpenFilePenality += uint16(customClassicEvaluator.parameters.SemiOpenFileBesideKingPenalty)
 	}
 
-	finalPenalty := int16(((threatPointOnSideToMoveKing + semipOpenFilePenality) * (threatPointOnSideToMoveKing + semipOpenFilePenality)) / 4)
-	if customClassicEvaluator.evaluationData.EnemyKingAttackerCount[color^1] >= 2 && position.PiecesBitBoard[color^1][chessEngine.Queen] != 0 {
-		customClassicEvaluator.evaluationData.MidgameScores[color] -= finalPenalty
+	shelterWeakness := customClassicEvaluator.evaluateKingShelter(position, color, square)
+	kingDanger := threatPointOnSideToMoveKing + semipOpenFilePenality + uint16(shelterWeakness)
+	finalPenalty := int16((kingDanger * kingDanger) / 4)
+	if evaluationData.EnemyKingAttackerCount[color^1] >= 2 && position.PiecesBitBoard[color^1][chessEngine.Queen] != 0 {
+		evaluationData.addScores(KingSafetyTerm, color, -finalPenalty, 0)
 	}
 }
 
-func (customClassicEvaluator *CustomEvaluator) evaluateAttacksOnEnemyKing(position *chessEngine.Position, moves chessEngine.Bitboard, color uint8, piece uint8) {
+// evaluateKingShelter returns the shelter weakness of the king: for the king's file and its two
+// neighbours (shifted inwards on the edge files), the shield table indexed by the rank of the
+// closest own pawn in front of the king, plus the storm table indexed by the rank of the closest
+// enemy pawn. A storming pawn that is blocked by the own shield pawn uses the blocked storm table.
+// Ranks count from the king's side, and index 0 stands for no pawn.
+func (customClassicEvaluator *CustomEvaluator) evaluateKingShelter(position *chessEngine.Position, color uint8, square uint8) int16 {
+	parameters := customClassicEvaluator.parameters
+	ownPawns := position.PiecesBitBoard[color][chessEngine.Pawn]
+	enemyPawns := position.PiecesBitBoard[color^1][chessEngine.Pawn]
+
+	centerFile := chessEngine.File(square)
+	if centerFile == chessEngine.FileA {
+		centerFile = chessEngine.FileB
+	} else if centerFile == chessEngine.FileH {
+		centerFile = chessEngine.FileG
+	}
+
+	var shelterWeakness int16
+	for file := centerFile - 1; file <= centerFile+1; file++ {
+		fileSquare := chessEngine.Rank(square)*8 + file
+		frontSpan := CheckDoublePawnOnSquareMask[color][fileSquare] | chessEngine.BitboardForSquare[fileSquare]
+
+		ownPawnRank, enemyPawnRank := uint8(0), uint8(0)
+		if frontSpan&ownPawns != 0 {
+			ownPawnRank = chessEngine.BoardRanksNormalAndFlipped[color][chessEngine.Rank(closestSquareFromSide(frontSpan&ownPawns, color))]
+		}
+		if frontSpan&enemyPawns != 0 {
+			enemyPawnRank = chessEngine.BoardRanksNormalAndFlipped[color][chessEngine.Rank(closestSquareFromSide(frontSpan&enemyPawns, color))]
+		}
+
+		shelterWeakness += parameters.KingShieldPenaltyByRank[ownPawnRank]
+		if ownPawnRank != 0 && enemyPawnRank == ownPawnRank+1 {
+			shelterWeakness += parameters.KingBlockedPawnStormPenaltyByRank[enemyPawnRank]
+		} else {
+			shelterWeakness += parameters.KingPawnStormPenaltyByRank[enemyPawnRank]
+		}
+	}
+	return shelterWeakness
+}
+
+// closestSquareFromSide returns the set square of the bitboard nearest to the first rank of color.
+func closestSquareFromSide(bitboard chessEngine.Bitboard, color uint8) uint8 {
+	if color == chessEngine.White {
+		return bitboard.MostSignificantBit()
+	}
+	return uint8(63 - bits.TrailingZeros64(uint64(bitboard)))
+}
+
+func (customClassicEvaluator *CustomEvaluator) evaluateAttacksOnEnemyKing(position *chessEngine.Position, evaluationData *EvaluationData, moves chessEngine.Bitboard, color uint8, piece uint8) {
 	var attacksOnEnemyKingOuterRing chessEngine.Bitboard = moves & KingSafetyZonesOnSquareMask[position.PiecesBitBoard[color^1][chessEngine.King].MostSignificantBit()].OuterDefenseRing
 	var attacksOnEnemyKingInnerRing chessEngine.Bitboard = moves & KingSafetyZonesOnSquareMask[position.PiecesBitBoard[color^1][chessEngine.King].MostSignificantBit()].InnerDefenseRing
 	if attacksOnEnemyKingOuterRing != 0 || attacksOnEnemyKingInnerRing != 0 {
-		customClassicEvaluator.evaluationData.EnemyKingAttackerCount[color]++
-		customClassicEvaluator.evaluationData.ThreatToEnemyKingPoints[color] += uint16(attacksOnEnemyKingOuterRing.CountSetBits()) * uint16(OuterRingAttackScorePerPiece[piece])
-		customClassicEvaluator.evaluationData.ThreatToEnemyKingPoints[color] += uint16(attacksOnEnemyKingInnerRing.CountSetBits()) * uint16(InnerRingAttackScorePerPiece[piece])
+		evaluationData.EnemyKingAttackerCount[color]++
+		evaluationData.ThreatToEnemyKingPoints[color] += uint16(attacksOnEnemyKingOuterRing.CountSetBits()) * uint16(customClassicEvaluator.parameters.OuterRingAttackScorePerPiece[piece])
+		evaluationData.ThreatToEnemyKingPoints[color] += uint16(attacksOnEnemyKingInnerRing.CountSetBits()) * uint16(customClassicEvaluator.parameters.InnerRingAttackScorePerPiece[piece])
 	}
 }
 
@@ -241,110 +798,50 @@ func filterMoveAndKeepTheSafeMoves(moves chessEngine.Bitboard, color uint8, enem
 	return safeMoves
 }
 
-func isDrawnState(position *chessEngine.Position) bool {
-	whiteKnightCount := position.PiecesBitBoard[chessEngine.White][chessEngine.Knight].CountSetBits()
-	whiteBishopCount := position.PiecesBitBoard[chessEngine.White][chessEngine.Bishop].CountSetBits()
-
-	blackKnightCount := position.PiecesBitBoard[chessEngine.Black][chessEngine.Knight].CountSetBits()
-	blackBishopCount := position.PiecesBitBoard[chessEngine.Black][chessEngine.Bishop].CountSetBits()
+// isRookShutInByKing reports whether the rook stands on its first rank between the corner and its
+// own king on f1/g1 (or b1/c1), with castling no longer possible to free it.
+func isRookShutInByKing(position *chessEngine.Position, color uint8, square uint8) bool {
+	castlingRights := uint8(chessEngine.White_Kingside_Castle_Right | chessEngine.White_Queenside_Castle_Right)
+	if color == chessEngine.Black {
+		castlingRights = chessEngine.Black_Kingside_Castle_Right | chessEngine.Black_Queenside_Castle_Right
+	}
+	if position.CastlingRights&castlingRights != 0 {
+		return false
+	}
 
-	totalPawnsCount := position.PiecesBitBoard[chessEngine.White][chessEngine.Pawn].CountSetBits() + position.PiecesBitBoard[chessEngine.Black][chessEngine.Pawn].CountSetBits()
-	totalKnightsCount := whiteKnightCount + blackKnightCount
-	totalBishopsCount := whiteBishopCount + blackBishopCount
-	totalRooksCount := position.PiecesBitBoard[chessEngine.White][chessEngine.Rook].CountSetBits() + position.PiecesBitBoard[chessEngine.Black][chessEngine.Rook].CountSetBits()
-	totalQueensCount := position.PiecesBitBoard[chessEngine.White][chessEngine.Queen].CountSetBits() + position.PiecesBitBoard[chessEngine.Black][chessEngine.Queen].CountSetBits()
+	relativeRookSquare := relativeSquare(color, square)
+	relativeKingSquare := relativeSquare(color, position.PiecesBitBoard[color][chessEngine.King].MostSignificantBit())
+	if chessEngine.Rank(relativeRookSquare) != chessEngine.Rank1 || chessEngine.Rank(relativeKingSquare) != chessEngine.Rank1 {
+		return false
+	}
+	kingFile, rookFile := chessEngine.File(relativeKingSquare), chessEngine.File(relativeRookSquare)
+	return ((kingFile == chessEngine.FileF || kingFile == chessEngine.FileG) && rookFile > kingFile) ||
+		((kingFile == chessEngine.FileB || kingFile == chessEngine.FileC) && rookFile < kingFile)
+}
 
-	majorPiecesCount := totalRooksCount + totalQueensCount
-	minorPiecesCount := totalKnightsCount + totalBishopsCount
+// relativeSquare returns the square as seen from white's side: black squares are flipped vertically.
+func relativeSquare(color uint8, square uint8) uint8 {
+	if color == chessEngine.Black {
+		return square ^ 56
+	}
+	return square
+}
 
-	if totalPawnsCount+majorPiecesCount+minorPiecesCount == 0 {
-		// King vs King
+// isSquareAttackedByColor reports whether any piece of attackerColor attacks the square, given the
+// board occupancy for sliding pieces.
+func isSquareAttackedByColor(position *chessEngine.Position, square uint8, attackerColor uint8, occupancy chessEngine.Bitboard) bool {
+	attackerPieces := &position.PiecesBitBoard[attackerColor]
+	if chessEngine.ComputedPawnCaptures[attackerColor^1][square]&attackerPieces[chessEngine.Pawn] != 0 ||
+		chessEngine.ComputedKnightMoves[square]&attackerPieces[chessEngine.Knight] != 0 ||
+		chessEngine.ComputedKingMoves[square]&attackerPieces[chessEngine.King] != 0 {
 		return true
-	} else if majorPiecesCount+totalPawnsCount == 0 {
-		if minorPiecesCount == 1 {
-			// King & minorPiece vs King
-			return true
-		} else if minorPiecesCount == 2 && whiteKnightCount == 1 && blackKnightCount == 1 {
-			// King & Knight vs King & Knight
-			return true
-		} else if minorPiecesCount == 2 && whiteBishopCount == 1 && blackBishopCount == 1 {
-			// King & Bishop vs King & Bishop and bishops are on the same square color
-			return isSquareLight(position.PiecesBitBoard[chessEngine.White][chessEngine.Bishop].MostSignificantBit()) == isSquareLight(position.PiecesBitBoard[chessEngine.Black][chessEngine.Bishop].MostSignificantBit())
-		}
 	}
-
-	return false
-}
-
-func isDrawishState(pos *chessEngine.Position) bool {
-	whiteKnightCount := pos.PiecesBitBoard[chessEngine.White][chessEngine.Knight].CountSetBits()
-	whiteBishopCount := pos.PiecesBitBoard[chessEngine.White][chessEngine.Bishop].CountSetBits()
-	whiteRookCount := pos.PiecesBitBoard[chessEngine.White][chessEngine.Rook].CountSetBits()
-	whiteQueenCount := pos.PiecesBitBoard[chessEngine.White][chessEngine.Queen].CountSetBits()
-
-	blackKnightCount := pos.PiecesBitBoard[chessEngine.Black][chessEngine.Knight].CountSetBits()
-	blackBishopCount := pos.PiecesBitBoard[chessEngine.Black][chessEngine.Bishop].CountSetBits()
-	blackRookCount := pos.PiecesBitBoard[chessEngine.Black][chessEngine.Rook].CountSetBits()
-	blackQueenCount := pos.PiecesBitBoard[chessEngine.Black][chessEngine.Queen].CountSetBits()
-
-	totalPawnsCount := pos.PiecesBitBoard[chessEngine.White][chessEngine.Pawn].CountSetBits() + pos.PiecesBitBoard[chessEngine.Black][chessEngine.Pawn].CountSetBits()
-	totalKnightsCount := whiteKnightCount + blackKnightCount
-	totalBishopsCount := whiteBishopCount + blackKnightCount
-	totalRooksCount := whiteRookCount + blackRookCount
-	totalQueensCount := whiteQueenCount + blackQueenCount
-
-	whiteMinorPiecesCount := whiteBishopCount + whiteKnightCount
-	blackMinorPiecesCount := blackBishopCount + blackKnightCount
-
-	totalMajorPiecesCount := totalRooksCount + totalQueensCount
-	totalMinorPiecesCount := totalKnightsCount + totalBishopsCount
-	totalPiecesCount := totalMajorPiecesCount + totalMinorPiecesCount
-
-	if totalPawnsCount == 0 {
-		if totalPiecesCount == 2 && blackQueenCount == 1 && whiteQueenCount == 1 {
-			// KQ v KQ
-			return true
-		} else if totalPiecesCount == 2 && blackRookCount == 1 && whiteRookCount == 1 {
-			// KR v KR
-			return true
-		} else if totalPiecesCount == 2 && whiteMinorPiecesCount == 1 && blackMinorPiecesCount == 1 {
-			// KN v KB
-			// KB v KB
-			return true
-		} else if totalPiecesCount == 3 && ((whiteQueenCount == 1 && blackRookCount == 2) || (blackQueenCount == 1 && whiteRookCount == 2)) {
-			// KQ v KRR
-			return true
-		} else if totalPiecesCount == 3 && ((whiteQueenCount == 1 && blackBishopCount == 2) || (blackQueenCount == 1 && whiteBishopCount == 2)) {
-			// KQ vs KBB
-			return true
-		} else if totalPiecesCount == 3 && ((whiteQueenCount == 1 && blackKnightCount == 2) || (blackQueenCount == 1 && whiteKnightCount == 2)) {
-			// KQ vs KNN
-			return true
-		} else if totalPiecesCount <= 3 && ((whiteKnightCount == 2 && blackMinorPiecesCount <= 1) || (blackKnightCount == 2 && whiteMinorPiecesCount <= 1)) {
-			// KNN v KN, KNN v KB, KNN v K
-			return true
-		} else if totalPiecesCount == 3 &&
-			((whiteQueenCount == 1 && blackRookCount == 1 && blackMinorPiecesCount == 1) ||
-				(blackQueenCount == 1 && whiteRookCount == 1 && whiteMinorPiecesCount == 1)) {
-			// KQ vs KRN, KQ vs KRB
-			return true
-		} else if totalPiecesCount == 3 &&
-			((whiteRookCount == 1 && blackRookCount == 1 && blackMinorPiecesCount == 1) ||
-				(blackRookCount == 1 && whiteRookCount == 1 && whiteMinorPiecesCount == 1)) {
-			// KR vs KRB, KR vs KRN
-		} else if totalPiecesCount == 4 &&
-			((whiteRookCount == 2 && blackRookCount == 1 && blackMinorPiecesCount == 1) ||
-				(blackRookCount == 2 && whiteRookCount == 1 && whiteMinorPiecesCount == 1)) {
-			// KRR v KRB, KRR v KRN
-			return true
-		}
+	if chessEngine.GetBishopPseudoLegalMoves(square, occupancy)&(attackerPieces[chessEngine.Bishop]|attackerPieces[chessEngine.Queen]) != 0 {
+		return true
 	}
-
-	return false
+	return chessEngine.GetRookPseudoLegalMoves(square, occupancy)&(attackerPieces[chessEngine.Rook]|attackerPieces[chessEngine.Queen]) != 0
 }
 
 func isSquareLight(square uint8) bool {
-	fileNumber := chessEngine.File(square)
-	rankNumber := chessEngine.File(square)
-	return (fileNumber+rankNumber)%2 != 0
+	return LightSquaresMask&chessEngine.BitboardForSquare[square] != 0
 }
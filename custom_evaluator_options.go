@@ -0,0 +1,76 @@
+package main
+
+import "strconv"
+
+// GetOptions offers every weight with a uci range as a spin option named after the weight. Values
+// outside the range are ignored, like values that are not numbers.
+// Piece values and square tables feed the incremental scores of chessEngine.Position, so changes
+// to those take effect from the next "position" command. "Style" switches between the built-in
+// play styles. "Pawn Hash Size" and "Eval Cache Size" set
+// the sizes of the evaluator's caches in MB, with 0 turning a cache off.
+func (evaluator *CustomEvaluator) GetOptions() map[string]EngineOption {
+	options := make(map[string]EngineOption)
+
+	for _, weight := range evaluator.parameters.Weights() {
+		minWeight, maxWeight, hasRange := weight.Range()
+		if !hasRange {
+			continue
+		}
+		weightName, weightValue := weight.Name, weight.Value
+		options[weight.Name] = EngineOption{
+			optionType:   "spin",
+			defaultValue: strconv.Itoa(int(*weightValue)),
+			minValue:     strconv.Itoa(int(minWeight)),
+			maxValue:     strconv.Itoa(int(maxWeight)),
+			setOption: func(optionValue string) {
+				newWeight, err := strconv.ParseInt(optionValue, 10, 16)
+				if err == nil && int16(newWeight) >= minWeight && int16(newWeight) <= maxWeight {
+					*weightValue = int16(newWeight)
+					evaluator.optionWeights[weightName] = int16(newWeight)
+					evaluator.ClearCaches()
+				}
+			},
+		}
+	}
+
+	styleNames := make([]string, len(EvaluationStyles))
+	for styleIndex, style := range EvaluationStyles {
+		styleNames[styleIndex] = style.Name
+	}
+	options["Style"] = EngineOption{
+		optionType:   "combo",
+		defaultValue: evaluator.StyleName(),
+		fixedValues:  styleNames,
+		setOption: func(optionValue string) {
+			evaluator.SetStyle(optionValue)
+		},
+	}
+
+	options["Pawn Hash Size"] = EngineOption{
+		optionType:   "spin",
+		defaultValue: strconv.Itoa(DefaultPawnHashTableSizeMB),
+		minValue:     "0",
+		maxValue:     strconv.Itoa(MaxPawnHashTableSizeMB),
+		setOption: func(optionValue string) {
+			sizeMB, err := strconv.Atoi(optionValue)
+			if err == nil && sizeMB >= 0 && sizeMB <= MaxPawnHashTableSizeMB {
+				evaluator.ResizePawnHashTable(sizeMB)
+			}
+		},
+	}
+
+	options["Eval Cache Size"] = EngineOption{
+		optionType:   "spin",
+		defaultValue: strconv.Itoa(DefaultEvaluationCacheSizeMB),
+		minValue:     "0",
+		maxValue:     strconv.Itoa(MaxEvaluationCacheSizeMB),
+		setOption: func(optionValue string) {
+			sizeMB, err := strconv.Atoi(optionValue)
+			if err == nil && sizeMB >= 0 && sizeMB <= MaxEvaluationCacheSizeMB {
+				evaluator.ResizeEvaluationCache(sizeMB)
+			}
+		},
+	}
+
+	return options
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/A7mad-2000as/GoFish/chessEngine"
+)
+
+// TestConcurrentEvaluatePosition hammers one shared evaluator from several goroutines, each with its
+// own positions, and compares every score with a reference taken from a separate evaluator. The
+// shared evaluator has no evaluation cache, so every call runs the full evaluation and the pawn
+// hash table is probed and filled from all goroutines at once. Run it with -race to let the race
+// detector watch the evaluator as well.
+func TestConcurrentEvaluatePosition(t *testing.T) {
+	const goroutineCount = 8
+	roundCount := 200
+	if testing.Short() {
+		roundCount = 20
+	}
+
+	parameters := DefaultEvaluationParameters
+	referenceEvaluator := NewCustomEvaluator(&parameters)
+	referenceScores := make([]int16, len(testPositions))
+	for fenIndex, fenString := range testPositions {
+		var position chessEngine.Position
+		position.LoadFEN(fenString, referenceEvaluator)
+		referenceScores[fenIndex] = referenceEvaluator.EvaluatePosition(&position)
+	}
+
+	evaluator := NewCustomEvaluator(&parameters)
+	evaluator.ResizeEvaluationCache(0)
+
+	var goroutinesGroup sync.WaitGroup
+	for goroutine := 0; goroutine < goroutineCount; goroutine++ {
+		goroutinesGroup.Add(1)
+		go func(goroutine int) {
+			defer goroutinesGroup.Done()
+			positions := make([]chessEngine.Position, len(testPositions))
+			for fenIndex, fenString := range testPositions {
+				positions[fenIndex].LoadFEN(fenString, evaluator)
+			}
+			for round := 0; round < roundCount; round++ {
+				for offset := range positions {
+					// Each goroutine walks the positions from a different starting point so that
+					// different positions are evaluated at the same time.
+					fenIndex := (offset + goroutine) % len(positions)
+					if score := evaluator.EvaluatePosition(&positions[fenIndex]); score != referenceScores[fenIndex] {
+						t.Errorf("goroutine %d: %s evaluated to %d instead of %d", goroutine, testPositions[fenIndex], score, referenceScores[fenIndex])
+						return
+					}
+				}
+			}
+		}(goroutine)
+	}
+	goroutinesGroup.Wait()
+}
+
+func TestUnstoppablePassedPawns(t *testing.T) {
+	testCases := []struct {
+		fenString       string
+		isUnstoppable   bool
+		descriptionText string
+	}{
+		{"k7/8/8/4p3/4P3/4K3/P7/8 b - - 0 1", false, "defending king on the promotion square"},
+		{"8/k7/8/4p3/4P3/4K3/P7/8 b - - 0 1", false, "defending king next to the promotion square"},
+		{"7k/8/8/4p3/4P3/4K3/P7/8 b - - 0 1", true, "defending king outside the square"},
+	}
+
+	parameters := DefaultEvaluationParameters
+	evaluator := NewCustomEvaluator(&parameters)
+	for _, testCase := range testCases {
+		t.Run(testCase.descriptionText, func(t *testing.T) {
+			var position chessEngine.Position
+			position.LoadFEN(testCase.fenString, evaluator)
+			trace := evaluator.TraceEvaluation(&position)
+			isUnstoppable := trace.EndgameScores[PassedPawnTerm][chessEngine.White] >= parameters.UnstoppablePassedPawnBonus
+			if isUnstoppable != testCase.isUnstoppable {
+				t.Errorf("%s: unstoppable %v, want %v\n%s", testCase.fenString, isUnstoppable, testCase.isUnstoppable, trace)
+			}
+		})
+	}
+}
+
+// TestKingShelterTables checks that the shield and storm tables raise the king danger of an
+// attacked king whose shelter is broken, and leave an intact shelter alone.
+func TestKingShelterTables(t *testing.T) {
+	testCases := []struct {
+		fenString       string
+		isWeakened      bool
+		descriptionText string
+	}{
+		{"r4rk1/ppp2p1p/3b4/6p1/3P2nq/2P2N1P/PP3P2/R2Q1RK1 w - - 0 1", true, "g-pawn gone and h-pawn pushed against a pawn storm"},
+		{"2kr3r/ppp2ppp/2n1b3/4q3/4P1n1/2NB1P1P/PPP3P1/R2Q1RK1 w - - 0 1", true, "f- and h-pawns pushed"},
+		{"r4rk1/ppp2ppp/3b4/8/3P2nq/2P2N2/PP3PPP/R2Q1RK1 w - - 0 1", false, "pawns on their home squares"},
+	}
+
+	parameters := DefaultEvaluationParameters
+	evaluator := NewCustomEvaluator(&parameters)
+	shelterlessParameters := DefaultEvaluationParameters
+	shelterlessParameters.KingShieldPenaltyByRank = [8]int16{}
+	shelterlessParameters.KingPawnStormPenaltyByRank = [8]int16{}
+	shelterlessParameters.KingBlockedPawnStormPenaltyByRank = [8]int16{}
+	shelterlessEvaluator := NewCustomEvaluator(&shelterlessParameters)
+
+	for _, testCase := range testCases {
+		t.Run(testCase.descriptionText, func(t *testing.T) {
+			var position chessEngine.Position
+			position.LoadFEN(testCase.fenString, evaluator)
+			kingSafety := evaluator.TraceEvaluation(&position).MidgameScores[KingSafetyTerm][chessEngine.White]
+			shelterlessKingSafety := shelterlessEvaluator.TraceEvaluation(&position).MidgameScores[KingSafetyTerm][chessEngine.White]
+			if shelterlessKingSafety == 0 {
+				t.Fatalf("%s: the king is not attacked enough to be scored", testCase.fenString)
+			}
+			if isWeakened := kingSafety < shelterlessKingSafety; isWeakened != testCase.isWeakened {
+				t.Errorf("%s: king safety %d with the shelter tables and %d without", testCase.fenString, kingSafety, shelterlessKingSafety)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import "github.com/A7mad-2000as/GoFish/chessEngine"
+
+const (
+	// KnownWinScore is added to mating-net evaluations so that they stay above any ordinary
+	// evaluation while remaining well below checkmate scores.
+	KnownWinScore int16 = 2000
+
+	pushToEdgeWeight   int16 = 20
+	pushCloseWeight    int16 = 20
+	pushToCornerWeight int16 = 100
+	KXKEndgameName           = "KXK"
+	KBNKEndgameName          = "KBNK"
+
+	// maxMatingMaterialScore caps the known win plus the strong side's material, which piece values
+	// set through profiles or UCI options can push past the int16 range. It leaves room below the
+	// mate scores for the largest king-driving bonus, so the drive still counts at the cap.
+	maxMatingMaterialScore = int32(chessEngine.MateThreshold) - 1 - int32(7*pushCloseWeight+14*pushToCornerWeight)
+)
+
+// evaluateMatingEndgame replaces the general evaluation when one side has only its king left
+// against a force that can mate it: a queen, a rook, a bishop and a knight, or bishops on both
+// colours. The score drives the lone king to the edge, or to a corner of the bishop's colour with
+// only a bishop and a knight, and brings the kings together. It returns the score from the side to
+// move's point of view and the name of the endgame, or false when the position is not such an
+// ending.
+func evaluateMatingEndgame(position *chessEngine.Position, parameters *EvaluationParameters) (int16, string, bool) {
+	for strongSide := chessEngine.Black; strongSide <= chessEngine.White; strongSide++ {
+		weakSide := strongSide ^ 1
+		weakKing := position.PiecesBitBoard[weakSide][chessEngine.King]
+		if position.ColorsBitBoard[weakSide] != weakKing {
+			continue
+		}
+
+		strongPieces := &position.PiecesBitBoard[strongSide]
+		knightCount, bishopCount := strongPieces[chessEngine.Knight].CountSetBits(), strongPieces[chessEngine.Bishop].CountSetBits()
+		hasBishopsOnBothColors := strongPieces[chessEngine.Bishop]&LightSquaresMask != 0 && strongPieces[chessEngine.Bishop]&DarkSquaresMask != 0
+		if strongPieces[chessEngine.Queen] == 0 && strongPieces[chessEngine.Rook] == 0 &&
+			!(knightCount != 0 && bishopCount != 0) && !hasBishopsOnBothColors {
+			continue
+		}
+
+		strongKingSquare := strongPieces[chessEngine.King].MostSignificantBit()
+		weakKingSquare := weakKing.MostSignificantBit()
+
+		materialScore := int32(KnownWinScore)
+		for pieceType := chessEngine.Pawn; pieceType <= chessEngine.Queen; pieceType++ {
+			materialScore += int32(strongPieces[pieceType].CountSetBits()) * int32(parameters.EndGamePieceValues[pieceType])
+		}
+		if materialScore > maxMatingMaterialScore {
+			materialScore = maxMatingMaterialScore
+		}
+		score := int16(materialScore) + pushCloseWeight*(7-int16(SquareDistances[strongKingSquare][weakKingSquare]))
+
+		endgameName := KXKEndgameName
+		if knightCount == 1 && bishopCount == 1 && position.ColorsBitBoard[strongSide]&^(strongPieces[chessEngine.King]|strongPieces[chessEngine.Knight]|strongPieces[chessEngine.Bishop]) == 0 {
+			// Mate with a bishop and a knight can only be forced in a corner of the bishop's colour.
+			endgameName = KBNKEndgameName
+			// The file and rank distances are added up rather than taking the king distance, so that
+			// walking along the edge from the wrong corner towards the right one always gains.
+			cornerDistance := minimumUint8(manhattanDistance(weakKingSquare, 0), manhattanDistance(weakKingSquare, 63))
+			if strongPieces[chessEngine.Bishop]&LightSquaresMask != 0 {
+				cornerDistance = minimumUint8(manhattanDistance(weakKingSquare, 7), manhattanDistance(weakKingSquare, 56))
+			}
+			score += pushToCornerWeight * (14 - int16(cornerDistance))
+		} else {
+			score += pushToEdgeWeight * int16(centerDistance(weakKingSquare))
+		}
+
+		if position.SideToMove != strongSide {
+			score = -score
+		}
+		return score, endgameName, true
+	}
+	return 0, "", false
+}
+
+// centerDistance counts the files and ranks between the square and the four central squares,
+// from 0 in the centre to 6 in a corner.
+func centerDistance(square uint8) uint8 {
+	fileDistance, rankDistance := chessEngine.File(square), chessEngine.Rank(square)
+	if fileDistance > 3 {
+		fileDistance = 7 - fileDistance
+	}
+	if rankDistance > 3 {
+		rankDistance = 7 - rankDistance
+	}
+	return (3 - fileDistance) + (3 - rankDistance)
+}
+
+func manhattanDistance(firstSquare uint8, secondSquare uint8) uint8 {
+	fileDistance := int(chessEngine.File(firstSquare)) - int(chessEngine.File(secondSquare))
+	rankDistance := int(chessEngine.Rank(firstSquare)) - int(chessEngine.Rank(secondSquare))
+	if fileDistance < 0 {
+		fileDistance = -fileDistance
+	}
+	if rankDistance < 0 {
+		rankDistance = -rankDistance
+	}
+	return uint8(fileDistance + rankDistance)
+}
+
+func minimumUint8(first uint8, second uint8) uint8 {
+	if first < second {
+		return first
+	}
+	return second
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/A7mad-2000as/GoFish/chessEngine"
+)
+
+// TestMatingEndgameStaysBelowMateScores gives the queens the largest value their option allows,
+// which takes the material of a mating net into the mate range unless it is capped, and checks
+// that the score still rewards bringing the kings together.
+func TestMatingEndgameStaysBelowMateScores(t *testing.T) {
+	parameters := DefaultEvaluationParameters
+	parameters.EndGamePieceValues[chessEngine.Queen] = 2000
+	evaluator := NewCustomEvaluator(&parameters)
+
+	var kingsApart, kingsTogether chessEngine.Position
+	kingsApart.LoadFEN("4k3/8/8/8/8/8/8/QQQQK3 w - - 0 1", evaluator)
+	kingsTogether.LoadFEN("4k3/8/4K3/8/8/8/8/QQQQ4 w - - 0 1", evaluator)
+	apartScore, togetherScore := evaluator.EvaluatePosition(&kingsApart), evaluator.EvaluatePosition(&kingsTogether)
+
+	for _, score := range []int16{apartScore, togetherScore} {
+		if score <= KnownWinScore || score > chessEngine.MateThreshold {
+			t.Errorf("KQQQQ vs K scored %d, want a known win below the mate threshold %d", score, chessEngine.MateThreshold)
+		}
+	}
+	if togetherScore <= apartScore {
+		t.Errorf("KQQQQ vs K scored %d with the kings together and %d with the kings apart", togetherScore, apartScore)
+	}
+}
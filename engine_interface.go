@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/A7mad-2000as/GoFish/chessEngine"
+)
+
+const (
+	MaxPerftDepth   = 6
+	mainMenuMessage = `
+Please enter a command:
+- uci : Start the UCI protocol to communicate with the engine
+- seeBoardState: Display the current board position
+- changePosition <fen>: Change the current position via an FEN string
+- perft <x>: Performance test of the move generation to depth x
+- dividePerft <x>: Divide performance test of the move generation to depth x
+- evaluatePosition: Get the static evaluation of the current position
+- eval / trace: Show every evaluation term of the current position
+- exit: Exit the main menu and quit the program`
+)
+
+// EngineInterface mirrors chessEngine.EngineInterface, but keeps the concrete CustomEvaluator so
+// that its weights can be offered as UCI options. The engine tables must already be initialized.
+type EngineInterface struct {
+	GameSearcher chessEngine.GameSearcher
+	Evaluator    *CustomEvaluator
+}
+
+func NewEngineInterface(gameSearcher chessEngine.GameSearcher, evaluator *CustomEvaluator) EngineInterface {
+	return EngineInterface{
+		GameSearcher: gameSearcher,
+		Evaluator:    evaluator,
+	}
+}
+
+func reflectFenString(position *chessEngine.Position, fenString string, evaluator chessEngine.Evaluator) {
+	defer func() {
+		if e := recover(); e != nil {
+			fmt.Println("Invalid FEN String")
+			fmt.Println(fenString)
+		}
+	}()
+
+	position.LoadFEN(fenString, evaluator)
+}
+
+func runPerft(perftCommand string, position *chessEngine.Position, evaluator chessEngine.Evaluator, divide bool) {
+	requiredDepth, e := strconv.Atoi(perftCommand)
+
+	if e != nil {
+		fmt.Println("Depth is invalid")
+		return
+	}
+
+	if requiredDepth > MaxPerftDepth {
+		fmt.Printf("Max value of depth is %v\n", MaxPerftDepth)
+		return
+	}
+
+	startTimeInstant := time.Now()
+	var numberOfVariations uint64
+	if divide {
+		numberOfVariations = chessEngine.DividePerft(position, uint8(requiredDepth), uint8(requiredDepth), evaluator)
+	} else {
+		numberOfVariations = chessEngine.Perft(position, uint8(requiredDepth), evaluator)
+	}
+	calculationTimeDuration := time.Since(startTimeInstant)
+
+	fmt.Printf("Number of variations: %v\n", numberOfVariations)
+	fmt.Printf("Execution time: %vs\n", calculationTimeDuration.Seconds())
+}
+
+func (engineInterface *EngineInterface) StartEngine() {
+	consoleReader := bufio.NewReader(os.Stdin)
+	uciInterface := UciInterface{
+		gameSearcher:  engineInterface.GameSearcher,
+		evaluator:     engineInterface.Evaluator,
+		consoleReader: consoleReader,
+	}
+
+	uciInterface.gameSearcher.InitializeSearchInfo(chessEngine.FENStartPosition, uciInterface.evaluator)
+	fmt.Println(mainMenuMessage)
+
+	for {
+		userCommand, readError := consoleReader.ReadString('\n')
+		command := strings.TrimSpace(strings.Replace(userCommand, "\r\n", "\n", -1))
+
+		if command == "uci" {
+			uciInterface.Run()
+			break
+		} else if command == "seeBoardState" {
+			fmt.Println(uciInterface.gameSearcher.Position())
+		} else if strings.HasPrefix(command, "changePosition") {
+			fenString := strings.TrimPrefix(command, "changePosition ")
+			reflectFenString(uciInterface.gameSearcher.Position(), strings.TrimSpace(fenString), uciInterface.evaluator)
+		} else if command == "exit" || readError != nil {
+			break
+		} else if strings.HasPrefix(command, "perft") {
+			runPerft(strings.TrimPrefix(command, "perft "), uciInterface.gameSearcher.Position(), engineInterface.Evaluator, false)
+		} else if strings.HasPrefix(command, "dividePerft") {
+			runPerft(strings.TrimPrefix(command, "dividePerft "), uciInterface.gameSearcher.Position(), engineInterface.Evaluator, true)
+		} else if command == "evaluatePosition" {
+			fmt.Println(uciInterface.evaluator.EvaluatePosition(uciInterface.gameSearcher.Position()))
+		} else if command == "eval" || command == "trace" {
+			fmt.Println(uciInterface.evaluator.TraceEvaluation(uciInterface.gameSearcher.Position()))
+		} else {
+			fmt.Println("Invalid input")
+			fmt.Println(mainMenuMessage)
+		}
+	}
+}
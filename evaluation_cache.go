@@ -0,0 +1,63 @@
+package main
+
+import "sync/atomic"
+
+const (
+	DefaultEvaluationCacheSizeMB = 16
+	MaxEvaluationCacheSizeMB     = 4096
+	EvaluationCacheEntrySize     = 16
+)
+
+// evaluationCacheEntry stores the position hash XORed with the data word, so an entry torn by a
+// concurrent write fails to match.
+type evaluationCacheEntry struct {
+	hashXorData atomic.Uint64
+	data        atomic.Uint64
+}
+
+type EvaluationCache struct {
+	cacheStatistics
+	entries   []evaluationCacheEntry
+	indexMask uint64
+}
+
+// NewEvaluationCache returns nil, which disables the cache, when sizeMB holds no entry.
+func NewEvaluationCache(sizeMB int) *EvaluationCache {
+	entryCount := cacheEntryCount(sizeMB, EvaluationCacheEntrySize)
+	if entryCount == 0 {
+		return nil
+	}
+	return &EvaluationCache{
+		entries:   make([]evaluationCacheEntry, entryCount),
+		indexMask: entryCount - 1,
+	}
+}
+
+func (cache *EvaluationCache) Probe(positionHash uint64) (int16, bool) {
+	cache.probeCount.Add(1)
+	entry := &cache.entries[positionHash&cache.indexMask]
+
+	data := entry.data.Load()
+	if entry.hashXorData.Load()^data != positionHash {
+		return 0, false
+	}
+
+	cache.hitCount.Add(1)
+	return int16(data), true
+}
+
+func (cache *EvaluationCache) Store(positionHash uint64, score int16) {
+	entry := &cache.entries[positionHash&cache.indexMask]
+	data := uint64(uint16(score))
+
+	entry.hashXorData.Store(positionHash ^ data)
+	entry.data.Store(data)
+}
+
+func (cache *EvaluationCache) Clear() {
+	for entryIndex := range cache.entries {
+		cache.entries[entryIndex].hashXorData.Store(0)
+		cache.entries[entryIndex].data.Store(0)
+	}
+	cache.resetStatistics()
+}
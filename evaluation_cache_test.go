@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/A7mad-2000as/GoFish/chessEngine"
+)
+
+// TestEvaluationCaches checks that scores read back from the pawn hash table and evaluation cache
+// match the scores of an evaluator that always computes everything from scratch. The first pass
+// over a position hits neither cache, later passes hit the evaluation cache, and the pawn hash
+// table is exercised by positions sharing a pawn structure and by an evaluator with the evaluation
+// cache turned off.
+func TestEvaluationCaches(t *testing.T) {
+	roundCount := 200
+	if testing.Short() {
+		roundCount = 20
+	}
+
+	parameters := DefaultEvaluationParameters
+	evaluator := NewCustomEvaluator(&parameters)
+	uncachedEvaluator := NewCustomEvaluator(&parameters)
+	uncachedEvaluator.ResizePawnHashTable(0)
+	uncachedEvaluator.ResizeEvaluationCache(0)
+	pawnCachedEvaluator := NewCustomEvaluator(&parameters)
+	pawnCachedEvaluator.ResizeEvaluationCache(0)
+
+	for round := 0; round < roundCount; round++ {
+		for _, fenString := range testPositions {
+			var position chessEngine.Position
+			position.LoadFEN(fenString, evaluator)
+			uncachedScore := uncachedEvaluator.EvaluatePosition(&position)
+			for _, cachedEvaluator := range [2]*CustomEvaluator{evaluator, pawnCachedEvaluator} {
+				if cachedScore := cachedEvaluator.EvaluatePosition(&position); cachedScore != uncachedScore {
+					t.Fatalf("%s evaluated to %d with caching and %d without", fenString, cachedScore, uncachedScore)
+				}
+			}
+		}
+	}
+
+	probeCount, hitCount, hitPercentage := pawnCachedEvaluator.pawnTable.HitRate()
+	t.Logf("pawn hash  %d probes %d hits (%.1f%%)", probeCount, hitCount, hitPercentage)
+	probeCount, hitCount, hitPercentage = evaluator.evaluationCache.HitRate()
+	t.Logf("eval cache %d probes %d hits (%.1f%%)", probeCount, hitCount, hitPercentage)
+}
@@ -0,0 +1,124 @@
+package main
+
+import "fmt"
+
+const DefaultStyleName = "Default"
+
+// EvaluationStyle is a named play style. Its profile lists the weights the style overrides, in the
+// same JSON format as an evaluation profile, and is applied on top of the evaluator's base weights.
+type EvaluationStyle struct {
+	Name    string
+	Profile string
+}
+
+// EvaluationStyles are the built-in presets offered by the Style option, in the order they are
+// listed to a GUI. The Default style keeps the base weights unchanged.
+var EvaluationStyles = []EvaluationStyle{
+	{Name: DefaultStyleName, Profile: `{}`},
+	{
+		// Pieces aimed at the enemy king count for more, and weak pawns are accepted for the
+		// initiative.
+		Name: "Aggressive",
+		Profile: `{
+			"OuterRingAttackScorePerPiece": [0, 2, 1, 1, 2],
+			"InnerRingAttackScorePerPiece": [0, 4, 5, 4, 3],
+			"MidGameKnightOnOutpostBonus": 32,
+			"MidGameBishopPairBonus": 34,
+			"EndGameBonusForRookOrQueenOnSeventhRank": 50,
+			"MidGameRookOnOpenFileBonus": 28,
+			"MidGameIsolatedPawnPenalty": 12,
+			"MidGameDoubledPawnPenalty": 0,
+			"MidGameTempoBonus": 18
+		}`,
+	},
+	{
+		// Long-term assets: outposts, the bishop pair and a healthy pawn structure.
+		Name: "Positional",
+		Profile: `{
+			"MidGameKnightOnOutpostBonus": 35,
+			"EndGameKnightOnOutpostBonus": 22,
+			"MidGameBishopOnOutpostBonus": 18,
+			"EndGameBishopOnOutpostBonus": 18,
+			"MidGameBishopPairBonus": 35,
+			"EndgameBishopPairBonus": 50,
+			"EndGameBonusForRookOrQueenOnSeventhRank": 35,
+			"MidGameIsolatedPawnPenalty": 22,
+			"EndGameIsolatedPawnPenalty": 10,
+			"MidGameDoubledPawnPenalty": 8,
+			"EndGameDoubledPawnPenalty": 20,
+			"MidGameBackwardPawnPenalty": 12,
+			"EndGameBackwardPawnPenalty": 16
+		}`,
+	},
+	{
+		// Keeps its own king covered and its pawns intact rather than attacking. The bishop pair
+		// and seventh rank bonuses are the values from before the aggressive bumps.
+		Name: "Solid",
+		Profile: `{
+			"OuterRingAttackScorePerPiece": [0, 1, 0, 0, 1],
+			"InnerRingAttackScorePerPiece": [0, 2, 3, 2, 1],
+			"SemiOpenFileBesideKingPenalty": 8,
+			"MidGameBishopPairBonus": 22,
+			"EndgameBishopPairBonus": 30,
+			"EndGameBonusForRookOrQueenOnSeventhRank": 23,
+			"MidGameIsolatedPawnPenalty": 22,
+			"EndGameIsolatedPawnPenalty": 10,
+			"MidGameDoubledPawnPenalty": 10,
+			"EndGameDoubledPawnPenalty": 20,
+			"MidGameBackwardPawnPenalty": 12,
+			"EndGameBackwardPawnPenalty": 16,
+			"MidGameTempoBonus": 10
+		}`,
+	},
+	{
+		// Heads for endings with lasting advantages: endgame weights go up, king attacks go down.
+		Name: "Endgame-grinder",
+		Profile: `{
+			"OuterRingAttackScorePerPiece": [0, 1, 0, 1, 1],
+			"InnerRingAttackScorePerPiece": [0, 2, 3, 2, 2],
+			"EndGameKnightOnOutpostBonus": 22,
+			"EndGameBishopOnOutpostBonus": 20,
+			"MidGameBishopPairBonus": 25,
+			"EndgameBishopPairBonus": 60,
+			"EndGameBonusForRookOrQueenOnSeventhRank": 55,
+			"EndGameIsolatedPawnPenalty": 14,
+			"EndGameDoubledPawnPenalty": 24
+		}`,
+	},
+}
+
+func findEvaluationStyle(styleName string) (*EvaluationStyle, error) {
+	for styleIndex := range EvaluationStyles {
+		if EvaluationStyles[styleIndex].Name == styleName {
+			return &EvaluationStyles[styleIndex], nil
+		}
+	}
+	return nil, fmt.Errorf("unknown style %q", styleName)
+}
+
+// SetStyle replaces the evaluator's weights with its base weights plus the overrides of the named
+// style. Weights set through UCI options are applied last, so they survive any style switch.
+func (evaluator *CustomEvaluator) SetStyle(styleName string) error {
+	style, err := findEvaluationStyle(styleName)
+	if err != nil {
+		return err
+	}
+
+	styledParameters := evaluator.baseParameters
+	if err := styledParameters.applyProfile([]byte(style.Profile)); err != nil {
+		return fmt.Errorf("style %s: %w", style.Name, err)
+	}
+	for _, weight := range styledParameters.Weights() {
+		if optionValue, isSet := evaluator.optionWeights[weight.Name]; isSet {
+			*weight.Value = optionValue
+		}
+	}
+	*evaluator.parameters = styledParameters
+	evaluator.styleName = style.Name
+	evaluator.ClearCaches()
+	return nil
+}
+
+func (evaluator *CustomEvaluator) StyleName() string {
+	return evaluator.styleName
+}
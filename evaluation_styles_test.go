@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+// TestOptionWeightsSurviveStyleSwitch sets weights before and after a style switch, as a GUI that
+// sends its options in alphabetical order does, and checks that the style never undoes them.
+func TestOptionWeightsSurviveStyleSwitch(t *testing.T) {
+	parameters := DefaultEvaluationParameters
+	evaluator := NewCustomEvaluator(&parameters)
+	options := evaluator.GetOptions()
+
+	options["MidGameBishopPairBonus"].setOption("41")
+	options["MidGameTempoBonus"].setOption("7")
+	options["Style"].setOption("Aggressive")
+	options["MidGameDoubledPawnPenalty"].setOption("9")
+	options["Style"].setOption("Solid")
+
+	if evaluator.StyleName() != "Solid" {
+		t.Fatalf("style %s, want Solid", evaluator.StyleName())
+	}
+	if bonus := evaluator.parameters.MidGameBishopPairBonus; bonus != 41 {
+		t.Errorf("MidGameBishopPairBonus %d after the style switches, want 41", bonus)
+	}
+	if bonus := evaluator.parameters.MidGameTempoBonus; bonus != 7 {
+		t.Errorf("MidGameTempoBonus %d after the style switches, want 7", bonus)
+	}
+	if penalty := evaluator.parameters.MidGameDoubledPawnPenalty; penalty != 9 {
+		t.Errorf("MidGameDoubledPawnPenalty %d after the style switch, want 9", penalty)
+	}
+	if penalty := evaluator.parameters.MidGameBackwardPawnPenalty; penalty != 12 {
+		t.Errorf("MidGameBackwardPawnPenalty %d, want the Solid style's 12", penalty)
+	}
+	if parameters != DefaultEvaluationParameters {
+		t.Error("the options wrote to the parameters the evaluator was created from")
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/A7mad-2000as/GoFish/chessEngine"
+)
+
+// testPositions is the corpus of the symmetry, concurrency and cache tests.
+var testPositions = []string{
+	chessEngine.FENStartPosition,
+	"r1bqkbnr/pppp1ppp/2n5/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R w KQkq - 2 3",
+	"r1bqk2r/pppp1ppp/2n2n2/2b1p3/2B1P3/3P1N2/PPP2PPP/RNBQK2R w KQkq - 1 5",
+	"rnbqkb1r/ppp1pppp/5n2/3pP3/8/8/PPPP1PPP/RNBQKBNR w KQkq d6 0 3",
+	"r2q1rk1/pp2bppp/2n1pn2/3p4/2PP4/2N1PN2/PP3PPP/R2QKB1R w KQ - 0 9",
+	"r1b2rk1/2q1bppp/p2ppn2/1p6/3BPP2/2N2B2/PPPQ2PP/2KR3R b - - 0 13",
+	"2r2rk1/1bqnbppp/p2ppn2/1p6/3NPP2/1BN1B3/PPP1Q1PP/R4RK1 w - - 4 14",
+	"r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+	"6k1/5ppp/8/8/8/8/5PPP/3R2K1 w - - 0 1",
+	"8/5pk1/6p1/8/3B4/8/5PPP/6K1 b - - 0 40",
+	"8/8/4k3/3p4/3P4/4K3/8/8 w - - 0 50",
+	"8/p7/1p3k2/8/8/6P1/5P1P/6K1 w - - 0 35",
+	"4k3/8/8/8/8/8/4P3/4K3 w - - 0 1",
+	"8/8/8/8/8/5k2/8/3BKN2 w - - 0 60",
+	"3r2k1/pp3ppp/8/8/8/8/PP3PPP/3R2K1 b - - 0 25",
+	"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+	"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+	"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+	"6k1/8/8/8/8/8/2q5/K7 w - - 0 70",
+	"2kr3r/ppp2ppp/2n5/2bqp3/8/2PP1N2/PP1Q1PPP/R1B1KB1R w KQ - 2 12",
+	"8/8/3k4/8/2n5/8/1Q2n3/4K3 w - - 0 60",
+	"8/3k4/8/2b5/4r3/8/3R1R2/4K3 b - - 0 55",
+	"8/2k5/8/3r4/8/2b5/3Q4/5K2 w - - 0 70",
+	"8/5k2/8/8/3B4/8/2b5/3K4 w - - 0 80",
+	"8/8/2k5/8/2N5/5n2/8/4K3 b - - 0 65",
+	"8/5k2/8/3R4/8/4K3/8/8 w - - 84 130",
+	"6k1/5p2/6p1/8/3r4/8/R4PPP/6K1 b - - 62 90",
+}
+
+// flipFENColors mirrors the board vertically and swaps the colours of all pieces, the side to
+// move, the castling rights and the en passant rank. The result is the same position seen from
+// the other side, so it must evaluate to the same side-to-move score.
+func flipFENColors(fenString string) string {
+	fenFields := completeFENFields(fenString)
+
+	boardRows := strings.Split(fenFields[0], "/")
+	for rowIndex, rowCount := 0, len(boardRows); rowIndex < rowCount/2; rowIndex++ {
+		boardRows[rowIndex], boardRows[rowCount-1-rowIndex] = boardRows[rowCount-1-rowIndex], boardRows[rowIndex]
+	}
+	fenFields[0] = swapLetterCase(strings.Join(boardRows, "/"))
+
+	if fenFields[1] == "w" {
+		fenFields[1] = "b"
+	} else {
+		fenFields[1] = "w"
+	}
+
+	if fenFields[2] != "-" {
+		var castlingBuilder strings.Builder
+		for _, castlingRight := range "KQkq" {
+			if strings.ContainsRune(swapLetterCase(fenFields[2]), castlingRight) {
+				castlingBuilder.WriteRune(castlingRight)
+			}
+		}
+		fenFields[2] = castlingBuilder.String()
+	}
+
+	if fenFields[3] != "-" {
+		fenFields[3] = fenFields[3][:1] + string(rune('1'+'8'-fenFields[3][1]))
+	}
+	return strings.Join(fenFields, " ")
+}
+
+// mirrorFENFiles mirrors the board horizontally (a-file to h-file). Castling rights do not survive
+// the mirror, so they are dropped; callers compare against the original with its rights dropped
+// as well.
+func mirrorFENFiles(fenString string) string {
+	fenFields := completeFENFields(fenString)
+
+	boardRows := strings.Split(fenFields[0], "/")
+	for rowIndex, boardRow := range boardRows {
+		rowRunes := []rune(boardRow)
+		for left, right := 0, len(rowRunes)-1; left < right; left, right = left+1, right-1 {
+			rowRunes[left], rowRunes[right] = rowRunes[right], rowRunes[left]
+		}
+		boardRows[rowIndex] = string(rowRunes)
+	}
+	fenFields[0] = strings.Join(boardRows, "/")
+	fenFields[2] = "-"
+
+	if fenFields[3] != "-" {
+		fenFields[3] = string(rune('a'+'h'-fenFields[3][0])) + fenFields[3][1:]
+	}
+	return strings.Join(fenFields, " ")
+}
+
+func withoutCastlingRights(fenString string) string {
+	fenFields := completeFENFields(fenString)
+	fenFields[2] = "-"
+	return strings.Join(fenFields, " ")
+}
+
+func completeFENFields(fenString string) []string {
+	fenFields := strings.Fields(fenString)
+	defaultFields := []string{"8/8/8/8/8/8/8/8", "w", "-", "-", "0", "1"}
+	for len(fenFields) < len(defaultFields) {
+		fenFields = append(fenFields, defaultFields[len(fenFields)])
+	}
+	return fenFields[:len(defaultFields)]
+}
+
+func swapLetterCase(text string) string {
+	return strings.Map(func(character rune) rune {
+		switch {
+		case character >= 'a' && character <= 'z':
+			return character - 'a' + 'A'
+		case character >= 'A' && character <= 'Z':
+			return character - 'A' + 'a'
+		}
+		return character
+	}, text)
+}
+
+// TestColorSymmetry checks every position against its colour-flipped and horizontally mirrored
+// versions. The colour flip must give the same score with the white and black terms swapped. The
+// square tables are not left-right symmetric, so mirrored positions are compared with an evaluator
+// whose tables have their queen side copied onto the king side; every term must then match.
+func TestColorSymmetry(t *testing.T) {
+	parameters := DefaultEvaluationParameters
+	evaluator := NewCustomEvaluator(&parameters)
+	mirrorParameters := mirrorSymmetricParameters(evaluator.parameters)
+	mirrorEvaluator := NewCustomEvaluator(&mirrorParameters)
+
+	for _, fenString := range testPositions {
+		fenString := fenString
+		t.Run(fenString, func(t *testing.T) {
+			t.Run("colour flip", func(t *testing.T) {
+				originalTrace := traceFEN(evaluator, fenString)
+				flippedFEN := flipFENColors(fenString)
+				flippedTrace := traceFEN(evaluator, flippedFEN)
+				termDifferences := describeTraceDifferences(originalTrace, flippedTrace, true)
+				if originalTrace.FinalScore != flippedTrace.FinalScore || termDifferences != "" {
+					t.Errorf("%s scores %d, %s scores %d%s", fenString, originalTrace.FinalScore, flippedFEN, flippedTrace.FinalScore, termDifferences)
+				}
+			})
+			t.Run("horizontal mirror", func(t *testing.T) {
+				uncastledFEN := withoutCastlingRights(fenString)
+				mirroredFEN := mirrorFENFiles(fenString)
+				uncastledTrace, mirroredTrace := traceFEN(mirrorEvaluator, uncastledFEN), traceFEN(mirrorEvaluator, mirroredFEN)
+				termDifferences := describeTraceDifferences(uncastledTrace, mirroredTrace, false)
+				if uncastledTrace.FinalScore != mirroredTrace.FinalScore || termDifferences != "" {
+					t.Errorf("%s scores %d, %s scores %d%s", uncastledFEN, uncastledTrace.FinalScore, mirroredFEN, mirroredTrace.FinalScore, termDifferences)
+				}
+			})
+		})
+	}
+}
+
+func traceFEN(evaluator *CustomEvaluator, fenString string) EvaluationTrace {
+	var position chessEngine.Position
+	position.LoadFEN(fenString, evaluator)
+	return evaluator.TraceEvaluation(&position)
+}
+
+// mirrorSymmetricParameters copies the parameters with every square table made left-right
+// symmetric.
+func mirrorSymmetricParameters(parameters *EvaluationParameters) EvaluationParameters {
+	symmetricParameters := *parameters
+	symmetricTables := []*[64]int16{&symmetricParameters.MidGamePassedPawnSquareTables, &symmetricParameters.EndGamePassedPawnSquareTables}
+	for pieceType := range symmetricParameters.MidGamePieceSquareTables {
+		symmetricTables = append(symmetricTables, &symmetricParameters.MidGamePieceSquareTables[pieceType], &symmetricParameters.EndGamePieceSquareTables[pieceType])
+	}
+	for _, symmetricTable := range symmetricTables {
+		for square := uint8(0); square < 64; square++ {
+			if chessEngine.File(square) >= 4 {
+				symmetricTable[square] = symmetricTable[square^7]
+			}
+		}
+	}
+	return symmetricParameters
+}
+
+// describeTraceDifferences lists the terms whose scores differ between the two traces, with the
+// colours swapped in the second trace when swapColors is set.
+func describeTraceDifferences(firstTrace EvaluationTrace, secondTrace EvaluationTrace, swapColors bool) string {
+	var differencesBuilder strings.Builder
+	for term := uint8(0); term < NumberOfEvaluationTerms; term++ {
+		for color := chessEngine.Black; color <= chessEngine.White; color++ {
+			secondColor := color
+			if swapColors {
+				secondColor ^= 1
+			}
+			firstScores := [2]int16{firstTrace.MidgameScores[term][color], firstTrace.EndgameScores[term][color]}
+			secondScores := [2]int16{secondTrace.MidgameScores[term][secondColor], secondTrace.EndgameScores[term][secondColor]}
+			if firstScores != secondScores {
+				fmt.Fprintf(&differencesBuilder, "\n    %s (%s): %v vs %v", EvaluationTermNames[term], colorName(color), firstScores, secondScores)
+			}
+		}
+	}
+	if firstTrace.KnownEndgame != secondTrace.KnownEndgame {
+		fmt.Fprintf(&differencesBuilder, "\n    known endgame: %q vs %q", firstTrace.KnownEndgame, secondTrace.KnownEndgame)
+	}
+	if firstTrace.MaterialScale != secondTrace.MaterialScale {
+		fmt.Fprintf(&differencesBuilder, "\n    material scale: %d vs %d", firstTrace.MaterialScale, secondTrace.MaterialScale)
+	}
+	return differencesBuilder.String()
+}
+
+func colorName(color uint8) string {
+	if color == chessEngine.White {
+		return "white"
+	}
+	return "black"
+}
@@ -2,7 +2,6 @@ module github.com/A7mad-2000as/GoFish/GoFishCustomEvaluatorCaseStudy
 
 go 1.20
 
-require (
-	github.com/A7mad-2000as/GoFish v0.0.0-20240120155837-d1c4c0a4c4f3 // indirect
-	golang.org/x/exp v0.0.0-20230522175609-2e198f4a06a1 // indirect
-)
+require github.com/A7mad-2000as/GoFish v0.0.0-20240120155837-d1c4c0a4c4f3
+
+require golang.org/x/exp v0.0.0-20230522175609-2e198f4a06a1 // indirect
@@ -0,0 +1,184 @@
+package main
+
+import "github.com/A7mad-2000as/GoFish/chessEngine"
+
+// The KPK bitbase holds one bit per position of king and pawn against king, set when the side with
+// the pawn wins. Positions are stored with the pawn side as white and the pawn on the a- to
+// d-files; other positions are flipped and mirrored onto these. The index is made of the side to
+// move (1 bit), the weak king square (6 bits), the strong king square (6 bits), the pawn file
+// (2 bits) and the pawn rank counted down from the seventh (0 to 5).
+const (
+	kpkPositionCount     = 2 * 64 * 64 * 4 * 6
+	kpkPawnAdvanceWeight = 20
+	KPKEndgameName       = "KPK"
+)
+
+var kpkBitbase [kpkPositionCount / 64]uint64
+
+const (
+	kpkInvalid uint8 = 0
+	kpkUnknown uint8 = 1
+	kpkDraw    uint8 = 2
+	kpkWin     uint8 = 4
+)
+
+func kpkIndex(sideToMove uint8, strongKingSquare uint8, weakKingSquare uint8, pawnSquare uint8) int {
+	return int(sideToMove) | int(weakKingSquare)<<1 | int(strongKingSquare)<<7 |
+		int(chessEngine.File(pawnSquare))<<13 | int(6-chessEngine.Rank(pawnSquare))<<15
+}
+
+// InitKPKBitbase generates the bitbase by retrograde analysis. The positions that are decided at
+// once (illegal ones, safe promotions, stalemates and captures of an undefended pawn) are
+// classified first; every other position is then settled from its successors, repeating the pass
+// until nothing changes. What is still undecided at the end can never be won, so it is a draw.
+func InitKPKBitbase() {
+	results := make([]uint8, kpkPositionCount)
+	type kpkPosition struct {
+		sideToMove, strongKingSquare, weakKingSquare, pawnSquare uint8
+	}
+	positions := make([]kpkPosition, kpkPositionCount)
+
+	for pawnSquare := uint8(8); pawnSquare < 56; pawnSquare++ {
+		if chessEngine.File(pawnSquare) > chessEngine.FileD {
+			continue
+		}
+		for strongKingSquare := uint8(0); strongKingSquare < 64; strongKingSquare++ {
+			for weakKingSquare := uint8(0); weakKingSquare < 64; weakKingSquare++ {
+				for _, sideToMove := range []uint8{chessEngine.White, chessEngine.Black} {
+					index := kpkIndex(sideToMove, strongKingSquare, weakKingSquare, pawnSquare)
+					positions[index] = kpkPosition{sideToMove, strongKingSquare, weakKingSquare, pawnSquare}
+					results[index] = classifyKPKPosition(sideToMove, strongKingSquare, weakKingSquare, pawnSquare)
+				}
+			}
+		}
+	}
+
+	for isChanged := true; isChanged; {
+		isChanged = false
+		for index, result := range results {
+			if result != kpkUnknown {
+				continue
+			}
+			kpk := positions[index]
+			results[index] = settleKPKPosition(results, kpk.sideToMove, kpk.strongKingSquare, kpk.weakKingSquare, kpk.pawnSquare)
+			isChanged = isChanged || results[index] != kpkUnknown
+		}
+	}
+
+	for index, result := range results {
+		if result == kpkWin {
+			kpkBitbase[index/64] |= 1 << (index % 64)
+		}
+	}
+}
+
+// classifyKPKPosition decides the positions that need no successors, with white holding the pawn.
+func classifyKPKPosition(sideToMove uint8, strongKingSquare uint8, weakKingSquare uint8, pawnSquare uint8) uint8 {
+	weakKingBitboard := chessEngine.BitboardForSquare[weakKingSquare]
+	pawnAttacks := chessEngine.ComputedPawnCaptures[chessEngine.White][pawnSquare]
+	if SquareDistances[strongKingSquare][weakKingSquare] <= 1 || strongKingSquare == pawnSquare || weakKingSquare == pawnSquare ||
+		(sideToMove == chessEngine.White && pawnAttacks&weakKingBitboard != 0) {
+		return kpkInvalid
+	}
+
+	promotionSquare := pawnSquare + 8
+	if sideToMove == chessEngine.White {
+		if chessEngine.Rank(pawnSquare) == chessEngine.Rank7 && strongKingSquare != promotionSquare &&
+			(SquareDistances[weakKingSquare][promotionSquare] > 1 || SquareDistances[strongKingSquare][promotionSquare] == 1) {
+			return kpkWin
+		}
+		return kpkUnknown
+	}
+
+	weakKingMoves := chessEngine.ComputedKingMoves[weakKingSquare] &^ (chessEngine.ComputedKingMoves[strongKingSquare] | pawnAttacks)
+	if weakKingMoves == 0 {
+		// Stalemate: the king is not in check, as that was ruled out above.
+		return kpkDraw
+	}
+	if weakKingMoves&chessEngine.BitboardForSquare[pawnSquare] != 0 {
+		// The pawn is attacked and undefended.
+		return kpkDraw
+	}
+	return kpkUnknown
+}
+
+// settleKPKPosition classifies a position from its successors: white wins if one of its moves wins,
+// black draws if one of its moves draws, and the position stays unknown while a deciding successor
+// is still unknown. Illegal successors count for nothing.
+func settleKPKPosition(results []uint8, sideToMove uint8, strongKingSquare uint8, weakKingSquare uint8, pawnSquare uint8) uint8 {
+	successorResults := kpkInvalid
+	if sideToMove == chessEngine.White {
+		kingMoves := chessEngine.ComputedKingMoves[strongKingSquare]
+		for kingMoves != 0 {
+			successorResults |= results[kpkIndex(chessEngine.Black, kingMoves.PopMostSignificantBit(), weakKingSquare, pawnSquare)]
+		}
+		// Promotions were settled by classifyKPKPosition.
+		pushSquare := pawnSquare + 8
+		if chessEngine.Rank(pawnSquare) < chessEngine.Rank7 && pushSquare != strongKingSquare && pushSquare != weakKingSquare {
+			successorResults |= results[kpkIndex(chessEngine.Black, strongKingSquare, weakKingSquare, pushSquare)]
+			doublePushSquare := pushSquare + 8
+			if chessEngine.Rank(pawnSquare) == chessEngine.Rank2 && doublePushSquare != strongKingSquare && doublePushSquare != weakKingSquare {
+				successorResults |= results[kpkIndex(chessEngine.Black, strongKingSquare, weakKingSquare, doublePushSquare)]
+			}
+		}
+
+		if successorResults&kpkWin != 0 {
+			return kpkWin
+		}
+		if successorResults&kpkUnknown != 0 {
+			return kpkUnknown
+		}
+		return kpkDraw
+	}
+
+	kingMoves := chessEngine.ComputedKingMoves[weakKingSquare]
+	for kingMoves != 0 {
+		successorResults |= results[kpkIndex(chessEngine.White, strongKingSquare, kingMoves.PopMostSignificantBit(), pawnSquare)]
+	}
+	if successorResults&kpkDraw != 0 {
+		return kpkDraw
+	}
+	if successorResults&kpkUnknown != 0 {
+		return kpkUnknown
+	}
+	return kpkWin
+}
+
+// probeKPK reports whether the side with the pawn wins, with the squares given from the board as
+// it stands and strongSide the colour of the pawn.
+func probeKPK(strongSide uint8, sideToMove uint8, strongKingSquare uint8, weakKingSquare uint8, pawnSquare uint8) bool {
+	if strongSide == chessEngine.Black {
+		strongKingSquare, weakKingSquare, pawnSquare = strongKingSquare^56, weakKingSquare^56, pawnSquare^56
+		sideToMove ^= 1
+	}
+	if chessEngine.File(pawnSquare) > chessEngine.FileD {
+		strongKingSquare, weakKingSquare, pawnSquare = strongKingSquare^7, weakKingSquare^7, pawnSquare^7
+	}
+	index := kpkIndex(sideToMove, strongKingSquare, weakKingSquare, pawnSquare)
+	return kpkBitbase[index/64]&(1<<(index%64)) != 0
+}
+
+// evaluateKPK scores a king and pawn against king from the bitbase: a known win, which grows as the
+// pawn advances, or a draw. It returns false when the material is anything else.
+func evaluateKPK(position *chessEngine.Position, parameters *EvaluationParameters) (int16, bool) {
+	allBitBoard := position.ColorsBitBoard[chessEngine.White] | position.ColorsBitBoard[chessEngine.Black]
+	allPawns := position.PiecesBitBoard[chessEngine.White][chessEngine.Pawn] | position.PiecesBitBoard[chessEngine.Black][chessEngine.Pawn]
+	if allBitBoard.CountSetBits() != 3 || allPawns.CountSetBits() != 1 {
+		return 0, false
+	}
+
+	pawnSquare := allPawns.MostSignificantBit()
+	strongSide := position.SquareContent[pawnSquare].Color
+	strongKingSquare := position.PiecesBitBoard[strongSide][chessEngine.King].MostSignificantBit()
+	weakKingSquare := position.PiecesBitBoard[strongSide^1][chessEngine.King].MostSignificantBit()
+	if !probeKPK(strongSide, position.SideToMove, strongKingSquare, weakKingSquare, pawnSquare) {
+		return drawScore, true
+	}
+
+	score := KnownWinScore + parameters.EndGamePieceValues[chessEngine.Pawn] +
+		kpkPawnAdvanceWeight*int16(chessEngine.BoardRanksNormalAndFlipped[strongSide][chessEngine.Rank(pawnSquare)])
+	if position.SideToMove != strongSide {
+		score = -score
+	}
+	return score, true
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/A7mad-2000as/GoFish/chessEngine"
+)
+
+// TestKPKBitbaseMatchesSearch compares the bitbase with a brute-force search on random legal KPK
+// positions of both colours. The search plays real moves, counts a promotion as won when the new
+// queen or rook can be neither taken nor stalemate the defender, and proves wins up to searchPlies
+// deep. Every bitbase win must be proved by the search, and no bitbase draw may be.
+func TestKPKBitbaseMatchesSearch(t *testing.T) {
+	const searchPlies = 60
+	sampleCount := 2000
+	if testing.Short() {
+		sampleCount = 200
+	}
+
+	parameters := DefaultEvaluationParameters
+	evaluator := NewCustomEvaluator(&parameters)
+	searcher := kpkSearcher{evaluator: evaluator, provedWins: map[uint64]int{}, failedProofs: map[uint64]int{}}
+	randomGenerator := rand.New(rand.NewSource(1))
+
+	winCount := 0
+	for sampleIndex := 0; sampleIndex < sampleCount; {
+		fenString, isLegal := randomKPKFEN(randomGenerator)
+		if !isLegal {
+			continue
+		}
+		sampleIndex++
+
+		var position chessEngine.Position
+		position.LoadFEN(fenString, evaluator)
+		score, _ := evaluateKPK(&position, evaluator.parameters)
+		isBitbaseWin := score != drawScore
+		if isBitbaseWin {
+			winCount++
+		}
+
+		if isSearchWin := searcher.isWon(&position, searchPlies); isBitbaseWin != isSearchWin {
+			t.Errorf("%s: bitbase win %v, search win %v", fenString, isBitbaseWin, isSearchWin)
+		}
+	}
+	t.Logf("%d positions (%d wins, %d draws) checked against a %d-ply search", sampleCount, winCount, sampleCount-winCount, searchPlies)
+}
+
+// randomKPKFEN places two kings and a pawn at random, with the pawn on either colour and either
+// side to move, and reports whether the result is a legal position.
+func randomKPKFEN(randomGenerator *rand.Rand) (string, bool) {
+	var board [64]byte
+	strongKingSquare, weakKingSquare := uint8(randomGenerator.Intn(64)), uint8(randomGenerator.Intn(64))
+	pawnSquare := uint8(8 + randomGenerator.Intn(48))
+	if SquareDistances[strongKingSquare][weakKingSquare] <= 1 || pawnSquare == strongKingSquare || pawnSquare == weakKingSquare {
+		return "", false
+	}
+	board[strongKingSquare], board[weakKingSquare], board[pawnSquare] = 'K', 'k', 'P'
+
+	// The side to move must not be able to take the opponent's king.
+	sideToMove := "w"
+	if randomGenerator.Intn(2) == 0 {
+		sideToMove = "b"
+	} else if chessEngine.ComputedPawnCaptures[chessEngine.White][pawnSquare]&chessEngine.BitboardForSquare[weakKingSquare] != 0 {
+		return "", false
+	}
+
+	var fenBuilder []byte
+	for rank := 7; rank >= 0; rank-- {
+		emptyCount := byte(0)
+		for file := 0; file < 8; file++ {
+			piece := board[rank*8+file]
+			if piece == 0 {
+				emptyCount++
+				continue
+			}
+			if emptyCount > 0 {
+				fenBuilder = append(fenBuilder, '0'+emptyCount)
+				emptyCount = 0
+			}
+			fenBuilder = append(fenBuilder, piece)
+		}
+		if emptyCount > 0 {
+			fenBuilder = append(fenBuilder, '0'+emptyCount)
+		}
+		if rank > 0 {
+			fenBuilder = append(fenBuilder, '/')
+		}
+	}
+
+	fenString := string(fenBuilder) + " " + sideToMove + " - - 0 1"
+	if randomGenerator.Intn(2) == 0 {
+		fenString = flipFENColors(fenString)
+	}
+	return fenString, true
+}
+
+// kpkSearcher proves KPK wins by an AND-OR search over real moves, remembering for each position
+// the shallowest depth a win was proved at and the deepest depth a proof failed at.
+type kpkSearcher struct {
+	evaluator    *CustomEvaluator
+	provedWins   map[uint64]int
+	failedProofs map[uint64]int
+}
+
+func (searcher *kpkSearcher) isWon(position *chessEngine.Position, plies int) bool {
+	if provedPlies, isProved := searcher.provedWins[position.PositionHash]; isProved && provedPlies <= plies {
+		return true
+	}
+	if failedPlies, isFailed := searcher.failedProofs[position.PositionHash]; isFailed && failedPlies >= plies {
+		return false
+	}
+
+	isWon := searcher.searchWin(position, plies)
+	if isWon {
+		searcher.provedWins[position.PositionHash] = plies
+	} else {
+		searcher.failedProofs[position.PositionHash] = plies
+	}
+	return isWon
+}
+
+func (searcher *kpkSearcher) searchWin(position *chessEngine.Position, plies int) bool {
+	strongSide := chessEngine.White
+	if position.PiecesBitBoard[chessEngine.White][chessEngine.Pawn] == 0 {
+		strongSide = chessEngine.Black
+	}
+	if position.PiecesBitBoard[strongSide][chessEngine.Pawn] == 0 || plies == 0 {
+		return false
+	}
+
+	legalMoves := generateLegalMoves(position, searcher.evaluator)
+	if position.SideToMove != strongSide {
+		// Every defence has to lose; no legal move means stalemate.
+		if len(legalMoves) == 0 {
+			return false
+		}
+		for _, move := range legalMoves {
+			position.DoMove(move, searcher.evaluator)
+			isWon := position.PiecesBitBoard[strongSide][chessEngine.Pawn] != 0 && searcher.isWon(position, plies-1)
+			position.UnDoPreviousMove(move, searcher.evaluator)
+			if !isWon {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, move := range legalMoves {
+		isWon := false
+		position.DoMove(move, searcher.evaluator)
+		if move.GetMoveType() == chessEngine.PromotionMoveType {
+			if move.GetMoveInfo() == chessEngine.PromotionToQueen || move.GetMoveInfo() == chessEngine.PromotionToRook {
+				isWon = isPromotionSafe(position, searcher.evaluator, move.GetToSquare())
+			}
+		} else {
+			isWon = searcher.isWon(position, plies-1)
+		}
+		position.UnDoPreviousMove(move, searcher.evaluator)
+		if isWon {
+			return true
+		}
+	}
+	return false
+}
+
+// isPromotionSafe reports whether the defender, to move, can neither take the promoted piece nor
+// is stalemated.
+func isPromotionSafe(position *chessEngine.Position, evaluator *CustomEvaluator, promotionSquare uint8) bool {
+	defenceMoves := generateLegalMoves(position, evaluator)
+	if len(defenceMoves) == 0 {
+		return position.IsCurrentSideInCheck()
+	}
+	for _, move := range defenceMoves {
+		if move.GetToSquare() == promotionSquare {
+			return false
+		}
+	}
+	return true
+}
@@ -1,12 +1,67 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"os"
+
 	"github.com/A7mad-2000as/GoFish/chessEngine"
 )
 
 func main() {
-	InitEvaluationRelatedMasks()
-	chessEngine.InitializeLateMoveReductions()
-	engineInterface := chessEngine.NewCustomEngineInterface(&chessEngine.DefaultSearcher{}, &CustomEvaluator{})
+	initializeEngineTables()
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "tune":
+			exitOnError(runTuneCommand(os.Args[2:]))
+			return
+		case "match":
+			exitOnError(runMatchCommand(os.Args[2:]))
+			return
+		}
+	}
+
+	profilePath := flag.String("profile", "", "JSON evaluation profile to load instead of the built-in weights")
+	styleName := flag.String("style", DefaultStyleName, "play style applied on top of the profile")
+	dumpProfilePath := flag.String("dump-profile", "", "write the loaded evaluation profile, without the style, to this file and exit")
+	flag.Parse()
+
+	defaultParameters := DefaultEvaluationParameters
+	evaluationParameters := &defaultParameters
+	if *profilePath != "" {
+		loadedParameters, err := LoadEvaluationParameters(*profilePath)
+		exitOnError(err)
+		evaluationParameters = loadedParameters
+	}
+
+	evaluator := NewCustomEvaluator(evaluationParameters)
+	exitOnError(evaluator.SetStyle(*styleName))
+
+	if *dumpProfilePath != "" {
+		exitOnError(SaveEvaluationParameters(*dumpProfilePath, evaluationParameters))
+		return
+	}
+
+	engineInterface := NewEngineInterface(&chessEngine.DefaultSearcher{}, evaluator)
 	engineInterface.StartEngine()
 }
+
+// initializeEngineTables fills the engine's move tables before the evaluation masks, which are
+// built from the file, rank and king move tables, then the material scale table and the KPK
+// bitbase, which needs the square distances.
+func initializeEngineTables() {
+	chessEngine.ComputePieceMoveTables()
+	chessEngine.InitializeZobristHashing()
+	chessEngine.InitializeLateMoveReductions()
+	InitEvaluationRelatedMasks()
+	InitMaterialScales()
+	InitKPKBitbase()
+}
+
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
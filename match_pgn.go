@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/A7mad-2000as/GoFish/chessEngine"
+)
+
+const pgnLineLength = 80
+
+var sanPieceLetters = [6]string{"", "N", "B", "R", "Q", "K"}
+
+func squareName(square uint8) string {
+	return string(rune('a'+chessEngine.File(square))) + string(rune('1'+chessEngine.Rank(square)))
+}
+
+// sanMove writes a legal move in standard algebraic notation, without the check suffix, which the
+// caller adds once the move has been played.
+func sanMove(position *chessEngine.Position, move chessEngine.Move, legalMoves []chessEngine.Move) string {
+	fromSquare, toSquare := move.GetFromSquare(), move.GetToSquare()
+	if move.GetMoveType() == chessEngine.CastleMoveType {
+		if chessEngine.File(toSquare) > chessEngine.File(fromSquare) {
+			return "O-O"
+		}
+		return "O-O-O"
+	}
+
+	pieceType := position.SquareContent[fromSquare].PieceType
+	isCapture := position.SquareContent[toSquare].PieceType != chessEngine.NoneType ||
+		(pieceType == chessEngine.Pawn && chessEngine.File(fromSquare) != chessEngine.File(toSquare))
+
+	var sanBuilder strings.Builder
+	if pieceType == chessEngine.Pawn {
+		if isCapture {
+			sanBuilder.WriteString(squareName(fromSquare)[:1])
+		}
+	} else {
+		sanBuilder.WriteString(sanPieceLetters[pieceType])
+
+		// Disambiguate by file if that is enough, then by rank, and by both otherwise.
+		sameFile, sameRank, isAmbiguous := false, false, false
+		for _, otherMove := range legalMoves {
+			otherFromSquare := otherMove.GetFromSquare()
+			if otherMove.GetToSquare() != toSquare || otherFromSquare == fromSquare || position.SquareContent[otherFromSquare].PieceType != pieceType {
+				continue
+			}
+			isAmbiguous = true
+			sameFile = sameFile || chessEngine.File(otherFromSquare) == chessEngine.File(fromSquare)
+			sameRank = sameRank || chessEngine.Rank(otherFromSquare) == chessEngine.Rank(fromSquare)
+		}
+		if isAmbiguous {
+			switch {
+			case !sameFile:
+				sanBuilder.WriteString(squareName(fromSquare)[:1])
+			case !sameRank:
+				sanBuilder.WriteString(squareName(fromSquare)[1:])
+			default:
+				sanBuilder.WriteString(squareName(fromSquare))
+			}
+		}
+	}
+
+	if isCapture {
+		sanBuilder.WriteString("x")
+	}
+	sanBuilder.WriteString(squareName(toSquare))
+	if move.GetMoveType() == chessEngine.PromotionMoveType {
+		sanBuilder.WriteString("=" + sanPieceLetters[chessEngine.Knight+move.GetMoveInfo()])
+	}
+	return sanBuilder.String()
+}
+
+// WritePgn writes every game with its opening as a SetUp/FEN tag pair, so each game can be
+// replayed from the position it started in.
+func WritePgn(pgnPath string, games []MatchGame) error {
+	gameDate := time.Now().Format("2006.01.02")
+
+	var pgnBuilder strings.Builder
+	for _, game := range games {
+		fmt.Fprintf(&pgnBuilder, "[Event \"GoFish evaluator match\"]\n[Site \"local\"]\n[Date \"%s\"]\n[Round \"%d\"]\n", gameDate, game.Round)
+		fmt.Fprintf(&pgnBuilder, "[White \"%s\"]\n[Black \"%s\"]\n[Result \"%s\"]\n", game.White, game.Black, game.Result)
+		if game.OpeningFEN != chessEngine.FENStartPosition {
+			fmt.Fprintf(&pgnBuilder, "[SetUp \"1\"]\n[FEN \"%s\"]\n", game.OpeningFEN)
+		}
+		fmt.Fprintf(&pgnBuilder, "[PlyCount \"%d\"]\n[Termination \"%s\"]\n\n", len(game.SanMoves), game.Termination)
+
+		openingFields := strings.Fields(game.OpeningFEN)
+		firstMoveNumber, _ := strconv.Atoi(openingFields[5])
+		if firstMoveNumber < 1 {
+			firstMoveNumber = 1
+		}
+		blackStarts := openingFields[1] == "b"
+		var movetextTokens []string
+		for plyIndex, san := range game.SanMoves {
+			// Counting plies from white's move of the first move number keeps the arithmetic the
+			// same whichever side starts.
+			fullPlyIndex := plyIndex
+			if blackStarts {
+				fullPlyIndex++
+			}
+			moveNumber := firstMoveNumber + fullPlyIndex/2
+			if fullPlyIndex%2 == 0 {
+				movetextTokens = append(movetextTokens, fmt.Sprintf("%d.", moveNumber))
+			} else if plyIndex == 0 {
+				movetextTokens = append(movetextTokens, fmt.Sprintf("%d...", moveNumber))
+			}
+			movetextTokens = append(movetextTokens, san)
+		}
+		movetextTokens = append(movetextTokens, game.Result)
+
+		lineLength := 0
+		for tokenIndex, token := range movetextTokens {
+			if tokenIndex > 0 && lineLength+1+len(token) > pgnLineLength {
+				pgnBuilder.WriteString("\n")
+				lineLength = 0
+			} else if tokenIndex > 0 {
+				pgnBuilder.WriteString(" ")
+				lineLength++
+			}
+			pgnBuilder.WriteString(token)
+			lineLength += len(token)
+		}
+		pgnBuilder.WriteString("\n\n")
+	}
+
+	return os.WriteFile(pgnPath, []byte(pgnBuilder.String()), 0644)
+}
@@ -0,0 +1,409 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/A7mad-2000as/GoFish/chessEngine"
+)
+
+const (
+	MaxMatchGamePlies = 800
+	matchUsage        = `usage: match -engine1 <player> -engine2 <player> [-openings <epd file>] [-games <n>] [flags]
+
+A player is "upstream" for chessEngine.DefaultEvaluator, "custom" for CustomEvaluator with the
+built-in weights, or a profile path, optionally followed by ":<style>", e.g. custom:Aggressive or
+profiles/solid.json:Solid. Both players search with chessEngine.DefaultSearcher. Every opening is
+played twice with colours reversed; results are reported from engine1's point of view.`
+)
+
+// MatchPlayer is one side of a match. newEvaluator is called once per worker, so every worker
+// plays with its own evaluator and, for custom players, its own caches on both sides.
+type MatchPlayer struct {
+	Name         string
+	newEvaluator func() chessEngine.Evaluator
+}
+
+// MatchLimits bounds each search of a match game. Zero values leave a limit off.
+type MatchLimits struct {
+	MoveTime  uint64
+	Depth     uint64
+	NodeCount uint64
+	MaxPlies  int
+}
+
+type MatchGame struct {
+	Round              int
+	FirstPlayerIsWhite bool
+	OpeningFEN         string
+	White              string
+	Black              string
+	SanMoves           []string
+	Result             string
+	Termination        string
+}
+
+// MatchResult counts wins, draws and losses from the first player's point of view.
+type MatchResult struct {
+	Wins   int
+	Draws  int
+	Losses int
+}
+
+func runMatchCommand(arguments []string) error {
+	matchFlags := flag.NewFlagSet("match", flag.ContinueOnError)
+	matchFlags.Usage = func() { fmt.Fprintln(matchFlags.Output(), matchUsage) }
+	firstPlayerSpec := matchFlags.String("engine1", "custom", "first player")
+	secondPlayerSpec := matchFlags.String("engine2", "upstream", "second player")
+	openingsPath := matchFlags.String("openings", "", "EPD file with one opening position per line (defaults to the start position)")
+	gameCount := matchFlags.Int("games", 0, "number of games, rounded up to an even number (defaults to two per opening)")
+	moveTime := matchFlags.Uint64("movetime", 100, "search time per move in milliseconds, 0 for none")
+	depth := matchFlags.Uint64("depth", 0, "search depth per move, 0 for none")
+	nodeCount := matchFlags.Uint64("nodes", 0, "searched nodes per move, 0 for none")
+	maxPlies := matchFlags.Int("maxplies", 400, "plies after which a game is adjudicated a draw")
+	workerCount := matchFlags.Int("concurrency", 1, "number of games played at the same time")
+	pgnPath := matchFlags.String("pgn", "match.pgn", "file all games are written to")
+	if err := matchFlags.Parse(arguments); err != nil {
+		return err
+	}
+	if *moveTime == 0 && *depth == 0 && *nodeCount == 0 {
+		return errors.New("match: one of -movetime, -depth or -nodes is required")
+	}
+	if *maxPlies <= 0 || *maxPlies > MaxMatchGamePlies {
+		return fmt.Errorf("match: -maxplies must be between 1 and %d", MaxMatchGamePlies)
+	}
+	if *workerCount < 1 {
+		return errors.New("match: -concurrency must be at least 1")
+	}
+
+	firstPlayer, err := parseMatchPlayer(*firstPlayerSpec)
+	if err != nil {
+		return err
+	}
+	secondPlayer, err := parseMatchPlayer(*secondPlayerSpec)
+	if err != nil {
+		return err
+	}
+
+	if firstPlayer.Name == secondPlayer.Name {
+		firstPlayer.Name, secondPlayer.Name = firstPlayer.Name+" #1", secondPlayer.Name+" #2"
+	}
+
+	openingFENs := []string{chessEngine.FENStartPosition}
+	if *openingsPath != "" {
+		if openingFENs, err = LoadOpeningPositions(*openingsPath); err != nil {
+			return err
+		}
+	}
+	if *gameCount <= 0 {
+		*gameCount = 2 * len(openingFENs)
+	}
+
+	limits := MatchLimits{MoveTime: *moveTime, Depth: *depth, NodeCount: *nodeCount, MaxPlies: *maxPlies}
+	games, result := playMatch(firstPlayer, secondPlayer, openingFENs, (*gameCount+1)/2, limits, *workerCount, os.Stdout)
+
+	fmt.Printf("\n%s vs %s: %d games\n", firstPlayer.Name, secondPlayer.Name, len(games))
+	fmt.Println(result)
+	if err := WritePgn(*pgnPath, games); err != nil {
+		return err
+	}
+	fmt.Printf("Games written to %s\n", *pgnPath)
+	return nil
+}
+
+func parseMatchPlayer(playerSpec string) (*MatchPlayer, error) {
+	if playerSpec == "upstream" {
+		chessEngine.InitEvaluationRelatedMasks()
+		return &MatchPlayer{
+			Name:         playerSpec,
+			newEvaluator: func() chessEngine.Evaluator { return &chessEngine.DefaultEvaluator{} },
+		}, nil
+	}
+
+	profilePath, styleName := playerSpec, DefaultStyleName
+	if separatorIndex := strings.LastIndex(playerSpec, ":"); separatorIndex != -1 {
+		profilePath, styleName = playerSpec[:separatorIndex], playerSpec[separatorIndex+1:]
+	}
+
+	parameters := DefaultEvaluationParameters
+	if profilePath != "custom" {
+		loadedParameters, err := LoadEvaluationParameters(profilePath)
+		if err != nil {
+			return nil, err
+		}
+		parameters = *loadedParameters
+	}
+	if _, err := findEvaluationStyle(styleName); err != nil {
+		return nil, err
+	}
+
+	playerName := strings.TrimSuffix(filepath.Base(profilePath), ".json")
+	if styleName != DefaultStyleName {
+		playerName += " (" + styleName + ")"
+	}
+	return &MatchPlayer{
+		Name: playerName,
+		newEvaluator: func() chessEngine.Evaluator {
+			evaluator := NewCustomEvaluator(&parameters)
+			evaluator.SetStyle(styleName)
+			return evaluator
+		},
+	}, nil
+}
+
+// LoadOpeningPositions reads the first four FEN fields of every EPD line; any operations after
+// them are ignored.
+func LoadOpeningPositions(openingsPath string) ([]string, error) {
+	epdLines, err := loadFenStrings(openingsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	openingFENs := make([]string, 0, len(epdLines))
+	for lineIndex, epdLine := range epdLines {
+		epdFields := strings.Fields(epdLine)
+		if len(epdFields) < 4 {
+			return nil, fmt.Errorf("%s: invalid opening %q", openingsPath, epdLines[lineIndex])
+		}
+		openingFENs = append(openingFENs, strings.Join(append(epdFields[:4], "0", "1"), " "))
+	}
+	if len(openingFENs) == 0 {
+		return nil, fmt.Errorf("%s: no openings found", openingsPath)
+	}
+	return openingFENs, nil
+}
+
+// playMatch plays pairCount pairs of games, cycling through the openings, and reports each result
+// to progressWriter. DefaultSearcher prints the info lines of every search to standard output as
+// well.
+func playMatch(firstPlayer *MatchPlayer, secondPlayer *MatchPlayer, openingFENs []string, pairCount int, limits MatchLimits, workerCount int, progressWriter io.Writer) ([]MatchGame, MatchResult) {
+	games := make([]MatchGame, 2*pairCount)
+	var result MatchResult
+	var resultMutex sync.Mutex
+	gameIndices := make(chan int)
+
+	var workersGroup sync.WaitGroup
+	for worker := 0; worker < workerCount; worker++ {
+		workersGroup.Add(1)
+		go func() {
+			defer workersGroup.Done()
+			players := [2]*matchGamePlayer{
+				newMatchGamePlayer(firstPlayer),
+				newMatchGamePlayer(secondPlayer),
+			}
+			for gameIndex := range gameIndices {
+				// The first player has white in the first game of every pair.
+				white, black := players[gameIndex%2], players[1-gameIndex%2]
+				game := playMatchGame(white, black, openingFENs[(gameIndex/2)%len(openingFENs)], limits)
+				game.Round, game.FirstPlayerIsWhite = gameIndex+1, gameIndex%2 == 0
+
+				resultMutex.Lock()
+				games[gameIndex] = game
+				result.addGame(game)
+				fmt.Fprintf(progressWriter, "Game %d: %s - %s %s (%s), %s\n", game.Round, game.White, game.Black, game.Result, game.Termination, result.Summary())
+				resultMutex.Unlock()
+			}
+		}()
+	}
+	for gameIndex := range games {
+		gameIndices <- gameIndex
+	}
+	close(gameIndices)
+	workersGroup.Wait()
+
+	return games, result
+}
+
+type matchGamePlayer struct {
+	name      string
+	evaluator chessEngine.Evaluator
+	searcher  chessEngine.DefaultSearcher
+}
+
+func newMatchGamePlayer(player *MatchPlayer) *matchGamePlayer {
+	gamePlayer := &matchGamePlayer{name: player.Name, evaluator: player.newEvaluator()}
+	gamePlayer.searcher.Reset(gamePlayer.evaluator)
+	return gamePlayer
+}
+
+// playMatchGame plays one game from the opening. Each player's searcher follows the game on its
+// own position, so its repetition history and incremental scores stay its own.
+func playMatchGame(white *matchGamePlayer, black *matchGamePlayer, openingFEN string, limits MatchLimits) MatchGame {
+	game := MatchGame{OpeningFEN: openingFEN, White: white.name, Black: black.name}
+	players := [2]*matchGamePlayer{chessEngine.Black: black, chessEngine.White: white}
+	for _, player := range players {
+		player.searcher.ResetToNewGame()
+		player.searcher.InitializeSearchInfo(openingFEN, player.evaluator)
+	}
+
+	var position chessEngine.Position
+	position.LoadFEN(openingFEN, white.evaluator)
+	positionCounts := map[uint64]int{position.PositionHash: 1}
+
+	depth, nodeCount := uint64(chessEngine.MaxDepth), uint64(math.MaxUint64)
+	if limits.Depth != 0 {
+		depth = limits.Depth
+	}
+	if limits.NodeCount != 0 {
+		nodeCount = limits.NodeCount
+	}
+
+	for {
+		legalMoves := generateLegalMoves(&position, white.evaluator)
+		if position.IsCurrentSideInCheck() && len(game.SanMoves) > 0 {
+			checkSuffix := "+"
+			if len(legalMoves) == 0 {
+				checkSuffix = "#"
+			}
+			game.SanMoves[len(game.SanMoves)-1] += checkSuffix
+		}
+
+		if len(legalMoves) == 0 {
+			if position.IsCurrentSideInCheck() {
+				game.Result, game.Termination = decisiveResult(position.SideToMove^1), "checkmate"
+			} else {
+				game.Result, game.Termination = "1/2-1/2", "stalemate"
+			}
+			return game
+		}
+		if position.Rule50 >= 100 {
+			game.Result, game.Termination = "1/2-1/2", "fifty-move rule"
+			return game
+		}
+		if positionCounts[position.PositionHash] >= 3 {
+			game.Result, game.Termination = "1/2-1/2", "threefold repetition"
+			return game
+		}
+		if hasInsufficientMaterial(&position) {
+			game.Result, game.Termination = "1/2-1/2", "insufficient material"
+			return game
+		}
+		if len(game.SanMoves) >= limits.MaxPlies {
+			game.Result, game.Termination = "1/2-1/2", "adjudicated after the ply limit"
+			return game
+		}
+
+		player := players[position.SideToMove]
+		player.searcher.InitializeTimeManager(int64(InfiniteTime), int64(NoValue), int64(limits.MoveTime), int16(NoValue), uint8(depth), nodeCount)
+		bestMove := player.searcher.StartSearch(player.evaluator)
+
+		move, isLegal := findLegalMove(&position, bestMove.String(), white.evaluator)
+		if bestMove == chessEngine.NullMove || !isLegal {
+			game.Result, game.Termination = decisiveResult(position.SideToMove^1), "illegal move "+bestMove.String()
+			return game
+		}
+
+		game.SanMoves = append(game.SanMoves, sanMove(&position, move, legalMoves))
+		position.DoMove(move, white.evaluator)
+		resetPositionStateStack(&position, white.evaluator)
+		positionCounts[position.PositionHash]++
+		for _, gamePlayer := range players {
+			searcherPosition := gamePlayer.searcher.Position()
+			searcherPosition.DoMove(move, gamePlayer.evaluator)
+			gamePlayer.searcher.RecordPositionHash(searcherPosition.PositionHash)
+			resetPositionStateStack(searcherPosition, gamePlayer.evaluator)
+		}
+	}
+}
+
+func generateLegalMoves(position *chessEngine.Position, evaluator chessEngine.Evaluator) []chessEngine.Move {
+	pseudoLegalMoves := chessEngine.GeneratePseudoLegalMoves(position)
+	legalMoves := make([]chessEngine.Move, 0, pseudoLegalMoves.Size)
+	for moveIndex := uint8(0); moveIndex < pseudoLegalMoves.Size; moveIndex++ {
+		move := pseudoLegalMoves.Moves[moveIndex]
+		if position.DoMove(move, evaluator) {
+			legalMoves = append(legalMoves, move)
+		}
+		position.UnDoPreviousMove(move, evaluator)
+	}
+	return legalMoves
+}
+
+// hasInsufficientMaterial recognises the dead positions neither side can win: bare kings, or a
+// single minor piece against a bare king.
+func hasInsufficientMaterial(position *chessEngine.Position) bool {
+	for color := chessEngine.Black; color <= chessEngine.White; color++ {
+		if position.PiecesBitBoard[color][chessEngine.Pawn]|position.PiecesBitBoard[color][chessEngine.Rook]|position.PiecesBitBoard[color][chessEngine.Queen] != 0 {
+			return false
+		}
+	}
+	allMinorPieces := position.PiecesBitBoard[chessEngine.White][chessEngine.Knight] | position.PiecesBitBoard[chessEngine.White][chessEngine.Bishop] |
+		position.PiecesBitBoard[chessEngine.Black][chessEngine.Knight] | position.PiecesBitBoard[chessEngine.Black][chessEngine.Bishop]
+	return allMinorPieces.CountSetBits() <= 1
+}
+
+func decisiveResult(winnerColor uint8) string {
+	if winnerColor == chessEngine.White {
+		return "1-0"
+	}
+	return "0-1"
+}
+
+func (result *MatchResult) addGame(game MatchGame) {
+	switch {
+	case game.Result == "1/2-1/2":
+		result.Draws++
+	case (game.Result == "1-0") == game.FirstPlayerIsWhite:
+		result.Wins++
+	default:
+		result.Losses++
+	}
+}
+
+// Elo estimates the rating difference from the score, with the half-width of a 95% confidence
+// interval computed from the per-game score variance.
+func (result MatchResult) Elo() (float64, float64) {
+	gameCount := float64(result.Wins + result.Draws + result.Losses)
+	if gameCount == 0 {
+		return 0, 0
+	}
+	score := (float64(result.Wins) + float64(result.Draws)/2) / gameCount
+	variance := (float64(result.Wins)*math.Pow(1-score, 2) + float64(result.Draws)*math.Pow(0.5-score, 2) + float64(result.Losses)*math.Pow(score, 2)) / gameCount
+	scoreMargin := 1.96 * math.Sqrt(variance/gameCount)
+	return eloFromScore(score), (eloFromScore(score+scoreMargin) - eloFromScore(score-scoreMargin)) / 2
+}
+
+func eloFromScore(score float64) float64 {
+	score = math.Min(math.Max(score, 1e-6), 1-1e-6)
+	return -400 * math.Log10(1/score-1)
+}
+
+func (result MatchResult) Summary() string {
+	return fmt.Sprintf("W/D/L %d/%d/%d", result.Wins, result.Draws, result.Losses)
+}
+
+func (result MatchResult) String() string {
+	gameCount := result.Wins + result.Draws + result.Losses
+	score := 0.0
+	if gameCount > 0 {
+		score = 100 * (float64(result.Wins) + float64(result.Draws)/2) / float64(gameCount)
+	}
+	elo, eloMargin := result.Elo()
+	return fmt.Sprintf("%s, score %.1f%%, Elo %+.1f +/- %.1f (95%%)", result.Summary(), score, elo, eloMargin)
+}
+
+func loadFenStrings(fensPath string) ([]string, error) {
+	fensFile, err := os.Open(fensPath)
+	if err != nil {
+		return nil, err
+	}
+	defer fensFile.Close()
+
+	var fenStrings []string
+	lineScanner := bufio.NewScanner(fensFile)
+	for lineScanner.Scan() {
+		line := strings.TrimSpace(lineScanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			fenStrings = append(fenStrings, line)
+		}
+	}
+	return fenStrings, lineScanner.Err()
+}
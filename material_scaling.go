@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/A7mad-2000as/GoFish/chessEngine"
+)
+
+// MaterialKey packs the number of pawns, knights, bishops, rooks and queens of both sides into
+// four bits each, black's in the low 20 bits and white's above them.
+type MaterialKey uint64
+
+const materialKeyBitsPerColor = 20
+
+var signaturePieceLetters = [5]byte{'P', 'N', 'B', 'R', 'Q'}
+
+// MaterialScaleEntry declares the scale factor, out of NormalScaleFactor, of every position with
+// the material of Signature. Signatures list white's pieces, then "v", then black's, e.g. KRvKRB;
+// each entry covers the colour-swapped material as well.
+type MaterialScaleEntry struct {
+	Signature string
+	Scale     int16
+}
+
+// MaterialScaleEntries are the known drawn (scale 0) and drawish endings. Material missing from
+// the table is scaled by NormalScaleFactor, that is not at all.
+var MaterialScaleEntries = []MaterialScaleEntry{
+	// Neither side can mate.
+	{Signature: "KvK", Scale: 0},
+	{Signature: "KNvK", Scale: 0},
+	{Signature: "KBvK", Scale: 0},
+	{Signature: "KNvKN", Scale: 0},
+	{Signature: "KBvKN", Scale: 0},
+	{Signature: "KBvKB", Scale: 0},
+
+	// Mate exists but cannot be forced against correct defence.
+	{Signature: "KNNvK", Scale: 2},
+	{Signature: "KNNvKN", Scale: 4},
+	{Signature: "KNNvKB", Scale: 4},
+
+	// Balanced or fortress-prone endings without pawns.
+	{Signature: "KQvKQ", Scale: 4},
+	{Signature: "KRvKR", Scale: 4},
+	{Signature: "KQvKRR", Scale: 4},
+	{Signature: "KQvKBB", Scale: 4},
+	{Signature: "KQvKNN", Scale: 4},
+	{Signature: "KQvKRN", Scale: 4},
+	{Signature: "KQvKRB", Scale: 4},
+	{Signature: "KRvKRN", Scale: 4},
+	{Signature: "KRvKRB", Scale: 4},
+	{Signature: "KRRvKRN", Scale: 4},
+	{Signature: "KRRvKRB", Scale: 4},
+	{Signature: "KRvKN", Scale: 16},
+	{Signature: "KRvKB", Scale: 16},
+}
+
+var materialScales map[MaterialKey]int16
+
+// InitMaterialScales builds the material key lookup from MaterialScaleEntries. It panics on a
+// malformed or contradictory entry, as the table is part of the program.
+func InitMaterialScales() {
+	materialScales = make(map[MaterialKey]int16, 2*len(MaterialScaleEntries))
+	for _, entry := range MaterialScaleEntries {
+		whiteKey, err := materialKeyFromSignature(entry.Signature)
+		if err != nil {
+			panic(err)
+		}
+		for _, materialKey := range []MaterialKey{whiteKey, whiteKey.swapColors()} {
+			if existingScale, isKnown := materialScales[materialKey]; isKnown && existingScale != entry.Scale {
+				panic(fmt.Sprintf("material signature %s is declared with scales %d and %d", entry.Signature, existingScale, entry.Scale))
+			}
+			materialScales[materialKey] = entry.Scale
+		}
+	}
+}
+
+func computeMaterialKey(position *chessEngine.Position) MaterialKey {
+	var materialKey MaterialKey
+	for color := chessEngine.Black; color <= chessEngine.White; color++ {
+		for pieceType := chessEngine.Pawn; pieceType <= chessEngine.Queen; pieceType++ {
+			pieceCount := MaterialKey(position.PiecesBitBoard[color][pieceType].CountSetBits())
+			materialKey |= pieceCount << (uint(color)*materialKeyBitsPerColor + uint(pieceType)*4)
+		}
+	}
+	return materialKey
+}
+
+// materialScale returns the scale factor declared for the position's material, or
+// NormalScaleFactor when the material is not in the table.
+func materialScale(position *chessEngine.Position) int16 {
+	if scale, isKnown := materialScales[computeMaterialKey(position)]; isKnown {
+		return scale
+	}
+	return NormalScaleFactor
+}
+
+func (materialKey MaterialKey) pieceCount(color uint8, pieceType uint8) int {
+	return int(materialKey>>(uint(color)*materialKeyBitsPerColor+uint(pieceType)*4)) & 0xF
+}
+
+func (materialKey MaterialKey) swapColors() MaterialKey {
+	colorMask := MaterialKey(1)<<materialKeyBitsPerColor - 1
+	return (materialKey&colorMask)<<materialKeyBitsPerColor | materialKey>>materialKeyBitsPerColor
+}
+
+// String writes the key as a signature such as KRvKRB, strongest pieces first.
+func (materialKey MaterialKey) String() string {
+	var signatureBuilder strings.Builder
+	for _, color := range []uint8{chessEngine.White, chessEngine.Black} {
+		if color == chessEngine.Black {
+			signatureBuilder.WriteString("v")
+		}
+		signatureBuilder.WriteString("K")
+		for pieceType := int(chessEngine.Queen); pieceType >= int(chessEngine.Pawn); pieceType-- {
+			signatureBuilder.WriteString(strings.Repeat(string(signaturePieceLetters[pieceType]), materialKey.pieceCount(color, uint8(pieceType))))
+		}
+	}
+	return signatureBuilder.String()
+}
+
+func materialKeyFromSignature(signature string) (MaterialKey, error) {
+	sides := strings.Split(signature, "v")
+	if len(sides) != 2 {
+		return 0, fmt.Errorf("material signature %q: want two sides separated by v", signature)
+	}
+
+	var materialKey MaterialKey
+	for sideIndex, color := range []uint8{chessEngine.White, chessEngine.Black} {
+		side := sides[sideIndex]
+		if !strings.HasPrefix(side, "K") || strings.Count(side, "K") != 1 {
+			return 0, fmt.Errorf("material signature %q: each side needs exactly one king, first", signature)
+		}
+		for _, pieceLetter := range []byte(side[1:]) {
+			pieceType := strings.IndexByte(string(signaturePieceLetters[:]), pieceLetter)
+			if pieceType < 0 {
+				return 0, fmt.Errorf("material signature %q: unknown piece %c", signature, pieceLetter)
+			}
+			materialKey += 1 << (uint(color)*materialKeyBitsPerColor + uint(pieceType)*4)
+		}
+	}
+	return materialKey, nil
+}
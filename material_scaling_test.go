@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestMaterialScales evaluates every signature of the table with each colour holding the first
+// side's material and each side to move. The scale must be the declared one, and a scale-0
+// signature must evaluate to a draw.
+func TestMaterialScales(t *testing.T) {
+	parameters := DefaultEvaluationParameters
+	evaluator := NewCustomEvaluator(&parameters)
+	for _, entry := range MaterialScaleEntries {
+		entry := entry
+		t.Run(entry.Signature, func(t *testing.T) {
+			fenStrings, err := signatureFENs(entry.Signature)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, fenString := range append(fenStrings, flipFENColors(fenStrings[0]), flipFENColors(fenStrings[1])) {
+				trace := traceFEN(evaluator, fenString)
+				if trace.MaterialScale != entry.Scale || (entry.Scale == 0 && trace.FinalScore != drawScore) {
+					t.Errorf("%s has scale %d and scores %d, want scale %d", fenString, trace.MaterialScale, trace.FinalScore, entry.Scale)
+				}
+			}
+		})
+	}
+}
+
+// signatureFENs places the material of a signature on squares where no piece attacks either
+// king: white's king on a1 and pieces on c1-f1 and c2-f2, black's the same, flipped.
+func signatureFENs(signature string) ([]string, error) {
+	sides := strings.Split(signature, "v")
+	if len(sides) != 2 || len(sides[0]) > 9 || len(sides[1]) > 9 {
+		return nil, fmt.Errorf("material signature %q cannot be placed", signature)
+	}
+
+	// Board rows from rank 8 down, with the free squares on files c to f.
+	boardRows := [8][]byte{}
+	for rowIndex := range boardRows {
+		boardRows[rowIndex] = []byte("........")
+	}
+	boardRows[0][7], boardRows[7][0] = 'k', 'K'
+	placePieces := func(pieces string, rowIndices [2]int, toCase func(byte) byte) {
+		for pieceIndex := 0; pieceIndex < len(pieces); pieceIndex++ {
+			boardRows[rowIndices[pieceIndex/4]][2+pieceIndex%4] = toCase(pieces[pieceIndex])
+		}
+	}
+	toLower := func(letter byte) byte { return letter - 'A' + 'a' }
+	placePieces(sides[0][1:], [2]int{7, 6}, func(letter byte) byte { return letter })
+	placePieces(sides[1][1:], [2]int{0, 1}, toLower)
+
+	var rowStrings []string
+	for _, boardRow := range boardRows {
+		var rowBuilder strings.Builder
+		emptyCount := 0
+		for _, square := range boardRow {
+			if square == '.' {
+				emptyCount++
+				continue
+			}
+			if emptyCount > 0 {
+				fmt.Fprintf(&rowBuilder, "%d", emptyCount)
+				emptyCount = 0
+			}
+			rowBuilder.WriteByte(square)
+		}
+		if emptyCount > 0 {
+			fmt.Fprintf(&rowBuilder, "%d", emptyCount)
+		}
+		rowStrings = append(rowStrings, rowBuilder.String())
+	}
+
+	board := strings.Join(rowStrings, "/")
+	return []string{board + " w - - 0 1", board + " b - - 0 1"}, nil
+}
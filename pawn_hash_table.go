@@ -0,0 +1,107 @@
+package main
+
+import (
+	"math/bits"
+	"sync/atomic"
+
+	"github.com/A7mad-2000as/GoFish/chessEngine"
+)
+
+const (
+	DefaultPawnHashTableSizeMB = 4
+	MaxPawnHashTableSizeMB     = 1024
+	PawnHashEntrySize          = 40
+)
+
+// PawnStructureEntry holds everything the evaluator derives from the pawns alone.
+type PawnStructureEntry struct {
+	MidgameScores [2]int16
+	EndgameScores [2]int16
+	PassedPawns   chessEngine.Bitboard
+}
+
+// pawnHashEntry is keyed by both pawn bitboards. The checksum word lets a reader reject an entry
+// torn by a concurrent write.
+type pawnHashEntry struct {
+	whitePawns atomic.Uint64
+	blackPawns atomic.Uint64
+	scores     atomic.Uint64
+	passed     atomic.Uint64
+	checksum   atomic.Uint64
+}
+
+type PawnHashTable struct {
+	cacheStatistics
+	entries   []pawnHashEntry
+	indexMask uint64
+}
+
+// NewPawnHashTable returns nil, which disables pawn caching, when sizeMB holds no entry.
+func NewPawnHashTable(sizeMB int) *PawnHashTable {
+	entryCount := cacheEntryCount(sizeMB, PawnHashEntrySize)
+	if entryCount == 0 {
+		return nil
+	}
+	return &PawnHashTable{
+		entries:   make([]pawnHashEntry, entryCount),
+		indexMask: entryCount - 1,
+	}
+}
+
+func hashPawnStructure(whitePawns chessEngine.Bitboard, blackPawns chessEngine.Bitboard) uint64 {
+	hash := uint64(whitePawns)*0x9e3779b97f4a7c15 ^ bits.RotateLeft64(uint64(blackPawns)*0xc2b2ae3d27d4eb4f, 31)
+	hash ^= hash >> 33
+	hash *= 0xff51afd7ed558ccd
+	hash ^= hash >> 33
+	return hash
+}
+
+func packPawnScores(entry *PawnStructureEntry) uint64 {
+	return uint64(uint16(entry.MidgameScores[chessEngine.Black])) |
+		uint64(uint16(entry.MidgameScores[chessEngine.White]))<<16 |
+		uint64(uint16(entry.EndgameScores[chessEngine.Black]))<<32 |
+		uint64(uint16(entry.EndgameScores[chessEngine.White]))<<48
+}
+
+func (table *PawnHashTable) Probe(whitePawns chessEngine.Bitboard, blackPawns chessEngine.Bitboard) (PawnStructureEntry, bool) {
+	table.probeCount.Add(1)
+	entry := &table.entries[hashPawnStructure(whitePawns, blackPawns)&table.indexMask]
+
+	storedWhitePawns, storedBlackPawns := entry.whitePawns.Load(), entry.blackPawns.Load()
+	scores, passed, checksum := entry.scores.Load(), entry.passed.Load(), entry.checksum.Load()
+	if storedWhitePawns != uint64(whitePawns) || storedBlackPawns != uint64(blackPawns) ||
+		checksum != storedWhitePawns^storedBlackPawns^scores^passed {
+		return PawnStructureEntry{}, false
+	}
+
+	table.hitCount.Add(1)
+	return PawnStructureEntry{
+		MidgameScores: [2]int16{int16(scores), int16(scores >> 16)},
+		EndgameScores: [2]int16{int16(scores >> 32), int16(scores >> 48)},
+		PassedPawns:   chessEngine.Bitboard(passed),
+	}, true
+}
+
+func (table *PawnHashTable) Store(whitePawns chessEngine.Bitboard, blackPawns chessEngine.Bitboard, pawnStructure *PawnStructureEntry) {
+	entry := &table.entries[hashPawnStructure(whitePawns, blackPawns)&table.indexMask]
+	scores := packPawnScores(pawnStructure)
+
+	entry.whitePawns.Store(uint64(whitePawns))
+	entry.blackPawns.Store(uint64(blackPawns))
+	entry.scores.Store(scores)
+	entry.passed.Store(uint64(pawnStructure.PassedPawns))
+	entry.checksum.Store(uint64(whitePawns) ^ uint64(blackPawns) ^ scores ^ uint64(pawnStructure.PassedPawns))
+}
+
+// Clear must be called whenever a pawn weight changes.
+func (table *PawnHashTable) Clear() {
+	for entryIndex := range table.entries {
+		entry := &table.entries[entryIndex]
+		entry.whitePawns.Store(0)
+		entry.blackPawns.Store(0)
+		entry.scores.Store(0)
+		entry.passed.Store(0)
+		entry.checksum.Store(0)
+	}
+	table.resetStatistics()
+}
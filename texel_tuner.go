@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/A7mad-2000as/GoFish/chessEngine"
+)
+
+const (
+	TunerMaxScalingConstant = 10.0
+	tuneUsage               = `usage: tune -data <positions file> [-profile <start profile>] [-out <tuned profile>] [-iterations <n>] [-k <scaling constant>]
+
+The positions file holds one quiet position per line with the game result, either as EPD
+(rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - c9 "1/2-1/2";), as a FEN followed by
+[1.0], [0.5] or [0.0], or as CSV (<fen>,<result>). Results are from white's point of view.`
+)
+
+var gameResultPattern = regexp.MustCompile(`1/2-1/2|1-0|0-1|\[\s*([01](?:\.\d+)?)\s*\]`)
+
+type TuningPosition struct {
+	FEN    string
+	Result float64
+}
+
+// TexelTuner fits the evaluation parameters to game results by minimising the mean squared error
+// between sigmoid(K * evaluation) and the result of each position, one weight step at a time.
+type TexelTuner struct {
+	parameters      *EvaluationParameters
+	evaluator       *CustomEvaluator
+	positions       []TuningPosition
+	scalingConstant float64
+}
+
+// NewTexelTuner runs its evaluator without the pawn hash table and evaluation cache, since every
+// weight step would invalidate the cached scores anyway. The tuner steps the evaluator's own copy of
+// the parameters.
+func NewTexelTuner(parameters *EvaluationParameters, positions []TuningPosition) *TexelTuner {
+	evaluator := NewCustomEvaluator(parameters)
+	evaluator.ResizePawnHashTable(0)
+	evaluator.ResizeEvaluationCache(0)
+	return &TexelTuner{
+		parameters: evaluator.parameters,
+		evaluator:  evaluator,
+		positions:  positions,
+	}
+}
+
+func runTuneCommand(arguments []string) error {
+	tuneFlags := flag.NewFlagSet("tune", flag.ContinueOnError)
+	tuneFlags.Usage = func() { fmt.Fprintln(tuneFlags.Output(), tuneUsage) }
+	dataPath := tuneFlags.String("data", "", "positions file with game results")
+	profilePath := tuneFlags.String("profile", "", "profile to start tuning from (defaults to the built-in weights)")
+	outputPath := tuneFlags.String("out", "tuned_profile.json", "file the tuned profile is written to after every iteration")
+	maxIterations := tuneFlags.Int("iterations", 100, "maximum number of passes over all weights")
+	scalingConstant := tuneFlags.Float64("k", 0, "sigmoid scaling constant, fitted to the data when 0")
+	if err := tuneFlags.Parse(arguments); err != nil {
+		return err
+	}
+	if *dataPath == "" {
+		tuneFlags.Usage()
+		return errors.New("tune: -data is required")
+	}
+
+	parameters := DefaultEvaluationParameters
+	if *profilePath != "" {
+		loadedParameters, err := LoadEvaluationParameters(*profilePath)
+		if err != nil {
+			return err
+		}
+		parameters = *loadedParameters
+	}
+
+	positions, err := LoadTuningPositions(*dataPath)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Loaded %d positions\n", len(positions))
+
+	tuner := NewTexelTuner(&parameters, positions)
+	if *scalingConstant == 0 {
+		tuner.FitScalingConstant()
+	} else {
+		tuner.scalingConstant = *scalingConstant
+	}
+	fmt.Printf("K = %.4f, initial error = %.8f\n", tuner.scalingConstant, tuner.MeanSquaredError())
+
+	return tuner.Tune(*maxIterations, *outputPath)
+}
+
+// LoadTuningPositions reads a positions file in any of the formats described by tuneUsage.
+func LoadTuningPositions(dataPath string) ([]TuningPosition, error) {
+	dataFile, err := os.Open(dataPath)
+	if err != nil {
+		return nil, err
+	}
+	defer dataFile.Close()
+
+	var positions []TuningPosition
+	lineScanner := bufio.NewScanner(dataFile)
+	for lineNumber := 1; lineScanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(lineScanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		position, err := parseTuningPosition(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", dataPath, lineNumber, err)
+		}
+		positions = append(positions, position)
+	}
+	if err := lineScanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(positions) == 0 {
+		return nil, fmt.Errorf("%s: no positions found", dataPath)
+	}
+	return positions, nil
+}
+
+func parseTuningPosition(line string) (TuningPosition, error) {
+	fenPart, resultPart := line, ""
+	if commaIndex := strings.LastIndex(line, ","); commaIndex != -1 {
+		fenPart, resultPart = line[:commaIndex], strings.TrimSpace(line[commaIndex+1:])
+	} else if resultMatch := gameResultPattern.FindStringIndex(line); resultMatch != nil {
+		fenPart, resultPart = line[:resultMatch[0]], line[resultMatch[0]:resultMatch[1]]
+	}
+
+	var result float64
+	switch resultPart = strings.Trim(resultPart, `[]" ;`); resultPart {
+	case "1-0":
+		result = 1
+	case "0-1":
+		result = 0
+	case "1/2-1/2":
+		result = 0.5
+	default:
+		parsedResult, err := strconv.ParseFloat(resultPart, 64)
+		if err != nil || parsedResult < 0 || parsedResult > 1 {
+			return TuningPosition{}, fmt.Errorf("missing or invalid game result %q", resultPart)
+		}
+		result = parsedResult
+	}
+
+	// EPD lines only carry the first four FEN fields, followed by operations such as c9.
+	fenFields := strings.Fields(fenPart)
+	if len(fenFields) < 4 {
+		return TuningPosition{}, fmt.Errorf("invalid position %q", fenPart)
+	}
+	halfMoveClock, fullMoveNumber := "0", "1"
+	if len(fenFields) >= 6 {
+		if _, err := strconv.Atoi(fenFields[4]); err == nil {
+			if _, err := strconv.Atoi(fenFields[5]); err == nil {
+				halfMoveClock, fullMoveNumber = fenFields[4], fenFields[5]
+			}
+		}
+	}
+
+	return TuningPosition{
+		FEN:    strings.Join(append(fenFields[:4], halfMoveClock, fullMoveNumber), " "),
+		Result: result,
+	}, nil
+}
+
+// FitScalingConstant picks the K that minimises the error for the current weights, narrowing the
+// search step by a factor of ten each round.
+func (tuner *TexelTuner) FitScalingConstant() {
+	bestScalingConstant, bestError := 0.0, math.MaxFloat64
+	searchStart, searchEnd := 0.0, TunerMaxScalingConstant
+	for step := 1.0; step >= 0.0001; step /= 10 {
+		for candidate := searchStart; candidate <= searchEnd; candidate += step {
+			tuner.scalingConstant = candidate
+			if candidateError := tuner.MeanSquaredError(); candidateError < bestError {
+				bestScalingConstant, bestError = candidate, candidateError
+			}
+		}
+		searchStart, searchEnd = math.Max(0, bestScalingConstant-step), bestScalingConstant+step
+	}
+	tuner.scalingConstant = bestScalingConstant
+}
+
+// MeanSquaredError evaluates every position with the current weights, split across one worker
+// per CPU. Positions are reloaded on each pass since piece values and square tables are baked
+// into the incremental scores of chessEngine.Position.
+func (tuner *TexelTuner) MeanSquaredError() float64 {
+	workerCount := runtime.NumCPU()
+	workerErrors := make([]float64, workerCount)
+
+	var workersGroup sync.WaitGroup
+	for worker := 0; worker < workerCount; worker++ {
+		workersGroup.Add(1)
+		go func(worker int) {
+			defer workersGroup.Done()
+			var position chessEngine.Position
+			for positionIndex := worker; positionIndex < len(tuner.positions); positionIndex += workerCount {
+				position.LoadFEN(tuner.positions[positionIndex].FEN, tuner.evaluator)
+				whiteScore := float64(tuner.evaluator.EvaluatePosition(&position))
+				if position.SideToMove == chessEngine.Black {
+					whiteScore = -whiteScore
+				}
+				predictedResult := 1 / (1 + math.Pow(10, -tuner.scalingConstant*whiteScore/400))
+				workerErrors[worker] += math.Pow(tuner.positions[positionIndex].Result-predictedResult, 2)
+			}
+		}(worker)
+	}
+	workersGroup.Wait()
+
+	totalError := 0.0
+	for _, workerError := range workerErrors {
+		totalError += workerError
+	}
+	return totalError / float64(len(tuner.positions))
+}
+
+// tunableWeights leaves out the fields tagged tune:"-" and the pawn entries no position can reach:
+// square table entries on the first and last ranks and connected pawn bonuses for those ranks.
+func (tuner *TexelTuner) tunableWeights() []EvaluationWeight {
+	parameters := tuner.parameters
+	unreachableWeights := make(map[*int16]bool)
+	for _, rank := range [2]int{0, 7} {
+		unreachableWeights[&parameters.MidGameConnectedPawnBonusPerRank[rank]] = true
+		unreachableWeights[&parameters.EndGameConnectedPawnBonusPerRank[rank]] = true
+		for file := 0; file < 8; file++ {
+			square := rank*8 + file
+			unreachableWeights[&parameters.MidGamePieceSquareTables[chessEngine.Pawn][square]] = true
+			unreachableWeights[&parameters.EndGamePieceSquareTables[chessEngine.Pawn][square]] = true
+			unreachableWeights[&parameters.MidGamePassedPawnSquareTables[square]] = true
+			unreachableWeights[&parameters.EndGamePassedPawnSquareTables[square]] = true
+		}
+	}
+
+	var weights []EvaluationWeight
+	for _, weight := range parameters.Weights() {
+		if weight.IsTunable && !unreachableWeights[weight.Value] {
+			weights = append(weights, weight)
+		}
+	}
+	return weights
+}
+
+// Tune runs the Texel local search: every tunable weight is nudged by +1 and then -1, keeping
+// whichever lowers the error, until a whole pass brings no improvement. Steps never leave a weight's
+// uci range. The profile is saved after each pass so a long run can be stopped at any time.
+func (tuner *TexelTuner) Tune(maxIterations int, outputPath string) error {
+	weights := tuner.tunableWeights()
+	bestError := tuner.MeanSquaredError()
+
+	for iteration := 1; iteration <= maxIterations; iteration++ {
+		improvedWeights := 0
+		for _, weight := range weights {
+			minWeight, maxWeight, hasRange := weight.Range()
+			if !hasRange {
+				minWeight, maxWeight = math.MinInt16, math.MaxInt16
+			}
+			originalValue := *weight.Value
+			for _, delta := range [2]int16{1, -1} {
+				if (delta > 0 && originalValue >= maxWeight) || (delta < 0 && originalValue <= minWeight) {
+					continue
+				}
+				*weight.Value = originalValue + delta
+				if candidateError := tuner.MeanSquaredError(); candidateError < bestError {
+					bestError = candidateError
+					improvedWeights++
+					break
+				}
+				*weight.Value = originalValue
+			}
+		}
+
+		fmt.Printf("Iteration %d: error = %.8f, %d weights changed\n", iteration, bestError, improvedWeights)
+		if err := SaveEvaluationParameters(outputPath, tuner.parameters); err != nil {
+			return err
+		}
+		if improvedWeights == 0 {
+			break
+		}
+	}
+
+	fmt.Printf("Tuned profile written to %s\n", outputPath)
+	return nil
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/A7mad-2000as/GoFish/chessEngine"
+)
+
+const (
+	InfiniteTime = -1
+	NoValue      = 0
+)
+
+type EngineOption struct {
+	optionType   string
+	defaultValue string
+	minValue     string
+	maxValue     string
+	fixedValues  []string
+	setOption    func(optionValue string)
+}
+
+type UciInterface struct {
+	gameSearcher         chessEngine.GameSearcher
+	evaluator            *CustomEvaluator
+	consoleReader        *bufio.Reader
+	engineOptions        map[string]EngineOption
+	showsCacheStatistics bool
+}
+
+func (uciInterface *UciInterface) ReInitialize() {
+	uciInterface.gameSearcher.Reset(uciInterface.evaluator)
+}
+
+func (uciInterface *UciInterface) respondToUciCommand() {
+	fmt.Println("id name", chessEngine.EngineName)
+	fmt.Println("id author", chessEngine.Author)
+
+	optionNames := make([]string, 0, len(uciInterface.engineOptions))
+	for optionName := range uciInterface.engineOptions {
+		optionNames = append(optionNames, optionName)
+	}
+	sort.Strings(optionNames)
+
+	for _, optionName := range optionNames {
+		option := uciInterface.engineOptions[optionName]
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("option name %s type %s ", optionName, option.optionType))
+		if option.optionType != "button" {
+			sb.WriteString(fmt.Sprintf("default %s ", option.defaultValue))
+		}
+
+		if option.optionType == "spin" {
+			sb.WriteString(fmt.Sprintf("min %s max %s ", option.minValue, option.maxValue))
+		} else if option.optionType == "combo" {
+			for _, fixedValue := range option.fixedValues {
+				sb.WriteString(fmt.Sprintf("var %s ", fixedValue))
+			}
+		}
+
+		fmt.Println(strings.TrimSpace(sb.String()))
+	}
+
+	fmt.Println("uciok")
+}
+
+func (uciInterface *UciInterface) respondToSetOptionCommand(setOptionCommand string) {
+	commandFields := strings.Fields(setOptionCommand)
+	gettingOptionValue := false
+
+	var optionNameBuilder strings.Builder
+	var optionValueBuilder strings.Builder
+	for _, commandField := range commandFields {
+		if commandField == "value" {
+			gettingOptionValue = true
+		} else if commandField != "name" && !gettingOptionValue {
+			optionNameBuilder.WriteString(commandField + " ")
+		} else if commandField != "name" {
+			optionValueBuilder.WriteString(commandField + " ")
+		}
+	}
+
+	optionName := strings.TrimSpace(optionNameBuilder.String())
+	optionValue := strings.TrimSpace(optionValueBuilder.String())
+
+	if option, optionExists := uciInterface.engineOptions[optionName]; optionExists {
+		option.setOption(optionValue)
+	}
+}
+
+func (uciInterface *UciInterface) respondToPositionCommand(positionCommand string) {
+	fenString := ""
+	movesString := ""
+
+	if strings.HasPrefix(positionCommand, "startpos") {
+		fenString = chessEngine.FENStartPosition
+		movesString = strings.TrimPrefix(positionCommand, "startpos ")
+	} else if strings.HasPrefix(positionCommand, "fen") {
+		commandInfo := strings.TrimPrefix(positionCommand, "fen ")
+		commandFields := strings.Fields(commandInfo)
+		if len(commandFields) < 6 {
+			return
+		}
+		fenString = strings.Join(commandFields[0:6], " ")
+		movesString = strings.Join(commandFields[6:], " ")
+	}
+
+	uciInterface.gameSearcher.InitializeSearchInfo(fenString, uciInterface.evaluator)
+
+	if strings.HasPrefix(movesString, "moves") {
+		position := uciInterface.gameSearcher.Position()
+		// Upstream pops every move off the position's undo stack, which is not exported here, so
+		// the stack is emptied by reloading the position whenever it fills up and after the moves.
+		stackedMoveCount := 0
+		for _, uciMove := range strings.Fields(strings.TrimPrefix(movesString, "moves ")) {
+			move, isFound := findMove(position, uciMove)
+			if !isFound {
+				fmt.Printf("info string unknown move %s\n", uciMove)
+				break
+			}
+			if stackedMoveCount == len(position.PreviousStates) {
+				resetPositionStateStack(position, uciInterface.evaluator)
+				stackedMoveCount = 0
+			}
+			position.DoMove(move, uciInterface.evaluator)
+			uciInterface.gameSearcher.RecordPositionHash(position.PositionHash)
+			stackedMoveCount++
+		}
+		if stackedMoveCount > 0 {
+			resetPositionStateStack(position, uciInterface.evaluator)
+		}
+	}
+}
+
+func (uciInterface *UciInterface) respondToGoCommand(goCommand string) {
+	commandFields := strings.Fields(goCommand)
+
+	colorIndicator := "b"
+	if uciInterface.gameSearcher.Position().SideToMove == chessEngine.White {
+		colorIndicator = "w"
+	}
+
+	remainingTime, increment, movesToGo := int(InfiniteTime), int(NoValue), int(NoValue)
+	depth, nodeCount, moveTime := uint64(chessEngine.MaxDepth), uint64(math.MaxUint64), uint64(NoValue)
+
+	for index, commandField := range commandFields {
+		if index+1 >= len(commandFields) {
+			break
+		}
+		switch commandField {
+		case "movestogo":
+			movesToGo, _ = strconv.Atoi(commandFields[index+1])
+		case "depth":
+			depth, _ = strconv.ParseUint(commandFields[index+1], 10, 8)
+		case "nodes":
+			nodeCount, _ = strconv.ParseUint(commandFields[index+1], 10, 64)
+		case "movetime":
+			moveTime, _ = strconv.ParseUint(commandFields[index+1], 10, 64)
+		case colorIndicator + "time":
+			remainingTime, _ = strconv.Atoi(commandFields[index+1])
+		case colorIndicator + "inc":
+			increment, _ = strconv.Atoi(commandFields[index+1])
+		}
+	}
+
+	uciInterface.gameSearcher.InitializeTimeManager(
+		int64(remainingTime),
+		int64(increment),
+		int64(moveTime),
+		int16(movesToGo),
+		uint8(depth),
+		nodeCount,
+	)
+
+	bestMoveEngineResponse := uciInterface.gameSearcher.StartSearch(uciInterface.evaluator)
+	if uciInterface.showsCacheStatistics {
+		uciInterface.printCacheStatistics()
+	}
+	fmt.Printf("bestmove %v\n", bestMoveEngineResponse)
+}
+
+func (uciInterface *UciInterface) printCacheStatistics() {
+	if pawnTable := uciInterface.evaluator.pawnTable; pawnTable != nil {
+		probeCount, hitCount, hitPercentage := pawnTable.HitRate()
+		fmt.Printf("info string pawn hash %d probes %d hits %.1f%%\n", probeCount, hitCount, hitPercentage)
+	}
+	if evaluationCache := uciInterface.evaluator.evaluationCache; evaluationCache != nil {
+		probeCount, hitCount, hitPercentage := evaluationCache.HitRate()
+		fmt.Printf("info string eval cache %d probes %d hits %.1f%%\n", probeCount, hitCount, hitPercentage)
+	}
+}
+
+// findMove matches a move in UCI notation against the pseudo-legal moves of the position.
+func findMove(position *chessEngine.Position, uciMove string) (chessEngine.Move, bool) {
+	pseudoLegalMoves := chessEngine.GeneratePseudoLegalMoves(position)
+	for moveIndex := uint8(0); moveIndex < pseudoLegalMoves.Size; moveIndex++ {
+		if move := pseudoLegalMoves.Moves[moveIndex]; move.String() == uciMove {
+			return move, true
+		}
+	}
+	return chessEngine.NullMove, false
+}
+
+// findLegalMove matches a move in UCI notation against the legal moves of the position.
+func findLegalMove(position *chessEngine.Position, uciMove string, evaluator chessEngine.Evaluator) (chessEngine.Move, bool) {
+	move, isFound := findMove(position, uciMove)
+	if !isFound {
+		return chessEngine.NullMove, false
+	}
+	isLegal := position.DoMove(move, evaluator)
+	position.UnDoPreviousMove(move, evaluator)
+	return move, isLegal
+}
+
+// resetPositionStateStack reloads the position from its own FEN. The undo stack of a position
+// only holds 100 states, so positions that are played forward move by move (rather than being
+// searched and unwound) have to drop it before it overflows.
+func resetPositionStateStack(position *chessEngine.Position, evaluator chessEngine.Evaluator) {
+	fenString := position.GenFEN()
+	*position = chessEngine.Position{}
+	position.LoadFEN(fenString, evaluator)
+}
+
+// moveOrderingTablesClearer is implemented by chessEngine.DefaultSearcher.
+type moveOrderingTablesClearer interface {
+	ClearKillerMoves()
+	ClearCounterMoves()
+	ClearHistoryHeuristicStats()
+}
+
+// searcherOptions offers the searcher controls that chessEngine exports. The transposition table
+// can only be resized from inside that package, so it keeps the searcher's default size.
+func searcherOptions(gameSearcher chessEngine.GameSearcher) map[string]EngineOption {
+	options := make(map[string]EngineOption)
+
+	options["Clear Hash"] = EngineOption{
+		optionType: "button",
+		setOption: func(_ string) {
+			gameSearcher.ResetToNewGame()
+		},
+	}
+
+	if tablesClearer, isClearer := gameSearcher.(moveOrderingTablesClearer); isClearer {
+		options["Clear Killer Moves"] = EngineOption{
+			optionType: "button",
+			setOption: func(_ string) {
+				tablesClearer.ClearKillerMoves()
+			},
+		}
+		options["Clear Counter Moves"] = EngineOption{
+			optionType: "button",
+			setOption: func(_ string) {
+				tablesClearer.ClearCounterMoves()
+			},
+		}
+		options["Clear History Heuristic Stats"] = EngineOption{
+			optionType: "button",
+			setOption: func(_ string) {
+				tablesClearer.ClearHistoryHeuristicStats()
+			},
+		}
+	}
+
+	return options
+}
+
+func (uciInterface *UciInterface) Run() {
+	uciInterface.engineOptions = searcherOptions(uciInterface.gameSearcher)
+	for optionName, option := range uciInterface.evaluator.GetOptions() {
+		uciInterface.engineOptions[optionName] = option
+	}
+	uciInterface.engineOptions["Cache Statistics"] = EngineOption{
+		optionType:   "check",
+		defaultValue: "false",
+		setOption: func(optionValue string) {
+			uciInterface.showsCacheStatistics = optionValue == "true"
+		},
+	}
+	uciInterface.respondToUciCommand()
+	uciInterface.ReInitialize()
+
+	for {
+		userCommand, readError := uciInterface.consoleReader.ReadString('\n')
+		command := strings.TrimSpace(strings.Replace(userCommand, "\r\n", "\n", -1))
+
+		if command == "uci" {
+			uciInterface.respondToUciCommand()
+		} else if strings.HasPrefix(command, "setoption") {
+			uciInterface.respondToSetOptionCommand(strings.TrimPrefix(command, "setoption "))
+		} else if command == "isready" {
+			fmt.Println("readyok")
+		} else if command == "ucinewgame" {
+			uciInterface.gameSearcher.ResetToNewGame()
+		} else if strings.HasPrefix(command, "position") {
+			uciInterface.respondToPositionCommand(strings.TrimPrefix(command, "position "))
+		} else if strings.HasPrefix(command, "go") {
+			go uciInterface.respondToGoCommand(strings.TrimPrefix(command, "go "))
+		} else if command == "eval" || command == "trace" {
+			fmt.Println(uciInterface.evaluator.TraceEvaluation(uciInterface.gameSearcher.Position()))
+		} else if command == "stop" {
+			uciInterface.gameSearcher.StopSearch()
+		} else if command == "quit" || readError != nil {
+			uciInterface.gameSearcher.StopSearch()
+			uciInterface.gameSearcher.CleanUp()
+			break
+		}
+	}
+}